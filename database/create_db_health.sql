@@ -1,8 +0,0 @@
-
--- Ceated Tablespace
-CREATE TABLESPACE health_ts 
-ADD DATAFILE '/Volumes/DATA/db/health/health_datafile.ibd' 
-ENGINE=INNODB;
-
--- Created database
-CREATE DATABASE health;
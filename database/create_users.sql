@@ -1,4 +1,6 @@
 CREATE USER 'health'@'%' IDENTIFIED BY 'XXXXXX';
 GRANT ALL PRIVILEGES ON health.* TO 'health'@'%' WITH GRANT OPTION;
 GRANT PROCESS ON *.* TO 'health'@'%';
+-- Lets schema migrate up create the tablespace
+GRANT CREATE TABLESPACE ON *.* TO 'health'@'%';
 FLUSH PRIVILEGES;
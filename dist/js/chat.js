@@ -0,0 +1,219 @@
+var chatBox = document.getElementById('chat-box');
+var currentStream = null;
+
+document.getElementById('send-button').onclick = function() {
+    var userInput = document.getElementById('user-input').value;
+    if (userInput) {
+        // Add user message, as text: it must never be parsed as HTML
+        var userMessage = document.createElement('div');
+        userMessage.className = 'message user-message';
+        userMessage.innerHTML = '<i class="fas fa-user"></i> <strong>You:</strong> ';
+        userMessage.appendChild(document.createTextNode(userInput));
+        chatBox.appendChild(userMessage);
+        document.getElementById('user-input').value = '';
+
+        var botMessage = document.createElement('div');
+        botMessage.className = 'message loading';
+        botMessage.innerHTML = '<div class="spinner"></div><strong>Bot:</strong> Réflexion...';
+        chatBox.appendChild(botMessage);
+
+        // The answer is streamed as it is generated: the text of each
+        // chunk is appended as is, then the done event replaces it with
+        // the answer rendered by the server
+        var finished = false;
+        var source = new EventSource('/chat/stream?message=' + encodeURIComponent(userInput) + profileQuery());
+        currentStream = source;
+
+        var render = function(data) {
+            if (!botMessage.classList.contains('bot-message')) {
+                botMessage.className = 'message bot-message';
+                botMessage.innerHTML = '<i class="fas fa-robot"></i> <strong>Bot:</strong> <div class="bot-content"></div>';
+            }
+            var content = botMessage.querySelector('.bot-content');
+            if (data.html) {
+                content.innerHTML = data.html;
+            } else {
+                content.textContent = data.text || data.error || '';
+            }
+            chatBox.scrollTop = chatBox.scrollHeight;
+        };
+
+        var finish = function() {
+            finished = true;
+            source.close();
+            if (currentStream === source) {
+                currentStream = null;
+            }
+            chatBox.insertAdjacentHTML('beforeend', '<div class="discussion-separator"></div>');
+        };
+
+        source.addEventListener('start', function(event) {
+            render(JSON.parse(event.data));
+        });
+        source.addEventListener('chunk', function(event) {
+            var data = JSON.parse(event.data);
+            var content = botMessage.querySelector('.bot-content');
+            if (!content) {
+                render({});
+                content = botMessage.querySelector('.bot-content');
+            }
+            var streaming = content.querySelector('.streaming');
+            if (!streaming) {
+                streaming = document.createElement('div');
+                streaming.className = 'streaming';
+                content.appendChild(streaming);
+            }
+            streaming.appendChild(document.createTextNode(data.delta || ''));
+            chatBox.scrollTop = chatBox.scrollHeight;
+        });
+        source.addEventListener('done', function(event) {
+            var data = JSON.parse(event.data);
+            render(data);
+            if (data.triage) {
+                // Red-flag symptoms: urgent-care guidance instead of an answer
+                botMessage.classList.add('triage', 'triage-' + data.triage.urgency);
+            }
+            renderExclusions(botMessage, data.exclusions);
+            renderDifferential(botMessage, data.differential);
+            renderSources(botMessage, data.sources);
+            finish();
+        });
+        source.addEventListener('error', function(event) {
+            if (finished) {
+                return;
+            }
+            // Errors sent by the server carry data, connection errors do not
+            render(event.data ? JSON.parse(event.data) : { error: 'The connection to the server was lost.' });
+            finish();
+        });
+    }
+
+
+};
+
+document.getElementById('clear-button').onclick = function() {
+    if (currentStream) {
+        currentStream.close(); // Stop the generation in progress
+        currentStream = null;
+    }
+    chatBox.innerHTML = ''; // Clear chat
+    fetch('/chat/clear', { method: 'POST' }); // End the conversation on the server
+};
+
+// profileQuery returns the patient profile of the form as parameters of
+// the stream URL
+function profileQuery() {
+    var query = '';
+    var add = function(name, value) {
+        if (value) {
+            query += '&' + name + '=' + encodeURIComponent(value);
+        }
+    };
+    add('age_band', document.getElementById('profile-age-band').value);
+    add('pregnant', document.getElementById('profile-pregnant').checked ? 'true' : '');
+    add('breastfeeding', document.getElementById('profile-breastfeeding').checked ? 'true' : '');
+    add('allergies', document.getElementById('profile-allergies').value.trim());
+    add('current_medications', document.getElementById('profile-medications').value.trim());
+    return query;
+}
+
+// renderExclusions lists the medications left out for the patient profile
+// below an answer, built as text like the sources
+function renderExclusions(message, exclusions) {
+    if (!exclusions || exclusions.length === 0) {
+        return;
+    }
+    var box = document.createElement('div');
+    box.className = 'exclusions';
+    var title = document.createElement('strong');
+    title.textContent = '🚫 Not suitable for this patient';
+    box.appendChild(title);
+
+    var list = document.createElement('ul');
+    exclusions.forEach(function(exclusion) {
+        var item = document.createElement('li');
+        item.textContent = exclusion.drug_name + ': ' + exclusion.reason;
+        list.appendChild(item);
+    });
+    box.appendChild(list);
+    message.appendChild(box);
+}
+
+// renderDifferential lists the pathologies whose symptoms the question
+// describes, with the symptoms matched, built as text like the sources
+function renderDifferential(message, differential) {
+    var matched = (differential || []).filter(function(candidate) {
+        return candidate.matched.length > 0;
+    });
+    if (matched.length === 0) {
+        return;
+    }
+    var box = document.createElement('div');
+    box.className = 'differential';
+    var title = document.createElement('strong');
+    title.textContent = '🔎 Matched symptoms';
+    box.appendChild(title);
+
+    var list = document.createElement('ul');
+    matched.forEach(function(candidate) {
+        var item = document.createElement('li');
+        var name = document.createElement('strong');
+        name.textContent = candidate.pathology + ' (' + Math.round(candidate.score * 100) + '%): ';
+        item.appendChild(name);
+        item.appendChild(document.createTextNode(candidate.matched.map(function(symptom) {
+            return symptom.symptom;
+        }).join(', ')));
+        list.appendChild(item);
+    });
+    box.appendChild(list);
+    message.appendChild(box);
+}
+
+// renderSources lists the medication labels of an answer as footnotes, so
+// its statements can be checked against the label text. Built as text: the
+// label fields must never be parsed as HTML.
+function renderSources(message, sources) {
+    if (!sources || sources.length === 0) {
+        return;
+    }
+    var footnotes = document.createElement('div');
+    footnotes.className = 'sources';
+    var title = document.createElement('strong');
+    title.textContent = 'Sources';
+    footnotes.appendChild(title);
+
+    var list = document.createElement('ol');
+    sources.forEach(function(source) {
+        var item = document.createElement('li');
+        item.value = source.marker;
+        if (!source.cited) {
+            item.className = 'uncited';
+            item.title = 'Not cited in this answer';
+        }
+
+        var name = source.drug_name + ' (#' + source.id + ')';
+        if (source.set_id) {
+            var link = document.createElement('a');
+            link.href = 'https://dailymed.nlm.nih.gov/dailymed/lookup.cfm?setid=' + encodeURIComponent(source.set_id);
+            link.target = '_blank';
+            link.rel = 'noopener noreferrer';
+            link.textContent = name;
+            item.appendChild(link);
+        } else {
+            item.appendChild(document.createTextNode(name));
+        }
+        var details = '';
+        if (source.ingredients && source.ingredients.length > 0) {
+            details += ' — ' + source.ingredients.join(' + ');
+        }
+        if (source.other_brands && source.other_brands.length > 0) {
+            details += ' — also sold as ' + source.other_brands.join(', ');
+        }
+        item.appendChild(document.createTextNode(details +
+            ' — ' + (source.sections.join(', ') || 'no label section') +
+            ' — similarity ' + source.similarity_score.toFixed(3)));
+        list.appendChild(item);
+    });
+    footnotes.appendChild(list);
+    message.appendChild(footnotes);
+}
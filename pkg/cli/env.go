@@ -0,0 +1,23 @@
+package cli
+
+import (
+	"fmt"
+
+	configPkg "github.com/colussim/go-mysql-ai/pkg/config"
+	"github.com/spf13/cobra"
+)
+
+var envCmd = &cobra.Command{
+	Use:   "env",
+	Short: "List the environment variables that override the configuration",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		for _, name := range configPkg.EnvNames() {
+			fmt.Println(name)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(envCmd)
+}
@@ -0,0 +1,81 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	configPkg "github.com/colussim/go-mysql-ai/pkg/config"
+	"github.com/colussim/go-mysql-ai/pkg/store"
+	"github.com/spf13/cobra"
+)
+
+// Export is the document written by the export command
+type Export struct {
+	EmbeddingModel     string             `json:"embedding_model,omitempty"`
+	EmbeddingDimension int                `json:"embedding_dimension,omitempty"`
+	Pathologies        []store.Pathology  `json:"pathologies"`
+	Medications        []store.Medication `json:"medications"`
+}
+
+var exportOutput string
+
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export the stored pathologies and medications as JSON",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		config, err := loadConfig()
+		if err != nil {
+			return err
+		}
+
+		vectorStore, err := store.Open(config)
+		if err != nil {
+			return fmt.Errorf("❌ Error initializing store: %w", err)
+		}
+		defer vectorStore.Close()
+
+		ctx := context.Background()
+		var export Export
+		export.EmbeddingModel, export.EmbeddingDimension, err = vectorStore.EmbeddingModel(ctx)
+		if err != nil {
+			return err
+		}
+		export.Pathologies, err = vectorStore.ListPathologies(ctx)
+		if err != nil {
+			return fmt.Errorf("❌ Error listing pathologies: %w", err)
+		}
+		export.Medications, err = vectorStore.ListMedications(ctx, 0)
+		if err != nil {
+			return fmt.Errorf("❌ Error listing medications: %w", err)
+		}
+
+		var out io.Writer = os.Stdout
+		if exportOutput != "" {
+			file, err := os.Create(exportOutput)
+			if err != nil {
+				return fmt.Errorf("❌ Error creating export file: %w", err)
+			}
+			defer file.Close()
+			out = file
+		}
+
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(export); err != nil {
+			return fmt.Errorf("❌ Error writing export: %w", err)
+		}
+		if exportOutput != "" {
+			configPkg.Log.Infof("✅ Exported %d pathologies and %d medications to %s\n", len(export.Pathologies), len(export.Medications), exportOutput)
+		}
+		return nil
+	},
+}
+
+func init() {
+	exportCmd.Flags().StringVarP(&exportOutput, "output", "o", "", "File to write the export to (default stdout)")
+	rootCmd.AddCommand(exportCmd)
+}
@@ -0,0 +1,46 @@
+package cli
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/briandowns/spinner"
+	configPkg "github.com/colussim/go-mysql-ai/pkg/config"
+	"github.com/colussim/go-mysql-ai/pkg/tools"
+	"github.com/spf13/cobra"
+)
+
+var importOptions tools.ImportOptions
+
+var importCmd = &cobra.Command{
+	Use:   "import",
+	Short: "Import the OpenFDA drug labels of the configured pathologies",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		config, err := loadConfig()
+		if err != nil {
+			return err
+		}
+
+		spin := spinner.New(spinner.CharSets[35], 100*time.Millisecond)
+		startTime := time.Now()
+
+		err = tools.RunImport(config, importOptions, spin)
+		spin.Stop()
+		if err != nil {
+			fmt.Println()
+			return fmt.Errorf("❌ Error Import Data : %w", err)
+		}
+
+		fmt.Println()
+		configPkg.Log.Infof("✅ Import completed in %s\n", formatDuration(time.Since(startTime)))
+		return nil
+	},
+}
+
+func init() {
+	importCmd.Flags().StringVar(&importOptions.BulkPath, "bulk", "", "OpenFDA drug label bulk file (.json.zip) or directory of partitions to import instead of querying the API")
+	importCmd.Flags().IntVar(&importOptions.Workers, "workers", 0, "Number of concurrent embedding requests (overrides import.workers)")
+	importCmd.Flags().StringVar(&importOptions.Resume, "resume", "", "ID of an interrupted import run to resume")
+	rootCmd.AddCommand(importCmd)
+}
@@ -0,0 +1,84 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+
+	configPkg "github.com/colussim/go-mysql-ai/pkg/config"
+	"github.com/colussim/go-mysql-ai/pkg/interactions"
+	"github.com/colussim/go-mysql-ai/pkg/store"
+	"github.com/spf13/cobra"
+)
+
+var interactionsCmd = &cobra.Command{
+	Use:   "interactions",
+	Short: "Manage the drug interaction table",
+}
+
+var interactionsImportCmd = &cobra.Command{
+	Use:   "import <file>",
+	Short: "Add or update the interactions of a CSV or JSON file",
+	Long: `Add or update the interactions of a CSV or JSON file. A CSV file has a
+header row with the columns ingredient_a, ingredient_b, severity,
+description and, optionally, source. A JSON file is an array of objects
+with the same fields. The severity is minor, moderate, major or
+contraindicated. Interactions are keyed on their pair of ingredients.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		table, err := interactions.Load(args[0])
+		if err != nil {
+			return err
+		}
+
+		return withInteractionStore(func(interactionStore store.InteractionStore) error {
+			if err := interactionStore.UpsertInteractions(context.Background(), table); err != nil {
+				return err
+			}
+			configPkg.Log.Infof("✅ Imported %d interactions from %s\n", len(table), args[0])
+			return nil
+		})
+	},
+}
+
+var interactionsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the stored interactions",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return withInteractionStore(func(interactionStore store.InteractionStore) error {
+			table, err := interactionStore.ListInteractions(context.Background())
+			if err != nil {
+				return err
+			}
+			for _, i := range table {
+				fmt.Printf("%s + %s: %s. %s\n", i.IngredientA, i.IngredientB, i.Severity, i.Description)
+			}
+			return nil
+		})
+	},
+}
+
+// withInteractionStore opens the configured store and runs fn with it
+func withInteractionStore(fn func(store.InteractionStore) error) error {
+	config, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	vectorStore, err := store.Open(config)
+	if err != nil {
+		return fmt.Errorf("❌ Error initializing store: %w", err)
+	}
+	defer vectorStore.Close()
+
+	interactionStore, ok := vectorStore.(store.InteractionStore)
+	if !ok {
+		return fmt.Errorf("❌ The %s store has no interaction table", config.Store.Type)
+	}
+	return fn(interactionStore)
+}
+
+func init() {
+	interactionsCmd.AddCommand(interactionsImportCmd, interactionsListCmd)
+	rootCmd.AddCommand(interactionsCmd)
+}
@@ -0,0 +1,121 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/colussim/go-mysql-ai/pkg/server"
+	"github.com/spf13/cobra"
+)
+
+var (
+	queryRetrieveOnly bool
+	queryProfile      server.PatientProfile
+)
+
+var queryCmd = &cobra.Command{
+	Use:   "query <question>",
+	Short: "Ask the chatbot a question from the command line",
+	Args:  cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		config, err := loadConfig()
+		if err != nil {
+			return err
+		}
+		if err := server.Setup(config); err != nil {
+			return err
+		}
+		defer server.Close()
+
+		ctx := cmd.Context()
+		if ctx == nil {
+			ctx = context.Background()
+		}
+		question := strings.Join(args, " ")
+
+		if queryRetrieveOnly {
+			match, medications, err := server.Retrieve(ctx, question)
+			if err != nil {
+				return err
+			}
+			if match == nil {
+				fmt.Println("No pathology recognized")
+				return nil
+			}
+			fmt.Printf("Pathology: %s (%.3f)\n", match.Name, match.SimilarityScore)
+			for _, med := range medications {
+				fmt.Printf("- %s (%.3f)", med.DrugName, med.SimilarityScore)
+				if len(med.Ingredients) > 0 {
+					fmt.Printf(" %s", strings.Join(med.Ingredients, " + "))
+				}
+				if len(med.OtherBrands) > 0 {
+					fmt.Printf(", also sold as %s", strings.Join(med.OtherBrands, ", "))
+				}
+				fmt.Println()
+			}
+			return nil
+		}
+
+		var profile *server.PatientProfile
+		if cmd.Flags().Changed("age-band") || queryProfile.Pregnant || queryProfile.Breastfeeding ||
+			len(queryProfile.Allergies) > 0 || len(queryProfile.CurrentMedications) > 0 {
+			if err := queryProfile.Validate(); err != nil {
+				return err
+			}
+			profile = &queryProfile
+		}
+
+		response, err := server.Ask(ctx, question, profile, func(chunk string) error {
+			_, err := fmt.Fprint(os.Stdout, chunk)
+			return err
+		})
+		if err != nil {
+			fmt.Println()
+			return err
+		}
+		if !response.Answered {
+			fmt.Print(response.Markdown)
+		}
+		fmt.Println()
+		for _, source := range response.Sources {
+			for _, caution := range source.Cautions {
+				fmt.Printf("⚠️ %s [%d]: %s\n", source.DrugName, source.Marker, caution)
+			}
+		}
+		if len(response.Interactions) > 0 {
+			fmt.Println("\n⚠️ Drug interactions:")
+			for _, w := range response.Interactions {
+				fmt.Printf("- %s: %s and %s (%s + %s). %s\n", w.Severity, w.Products[0], w.Products[1], w.IngredientA, w.IngredientB, w.Description)
+			}
+		}
+		for _, c := range response.Differential {
+			if len(c.Matched) == 0 {
+				continue
+			}
+			var matched []string
+			for _, s := range c.Matched {
+				matched = append(matched, s.Symptom)
+			}
+			fmt.Printf("🔎 %s (%.2f): %s\n", c.Pathology, c.Score, strings.Join(matched, ", "))
+		}
+		if len(response.Exclusions) > 0 {
+			fmt.Println("\n🚫 Not suitable for this patient:")
+			for _, e := range response.Exclusions {
+				fmt.Printf("- %s: %s\n", e.DrugName, e.Reason)
+			}
+		}
+		return nil
+	},
+}
+
+func init() {
+	queryCmd.Flags().BoolVar(&queryRetrieveOnly, "retrieve", false, "Only print the matched pathology and medications, without generating an answer")
+	queryCmd.Flags().StringVar(&queryProfile.AgeBand, "age-band", "", "Age band of the patient: infant, child, adolescent, adult or senior")
+	queryCmd.Flags().BoolVar(&queryProfile.Pregnant, "pregnant", false, "The patient is pregnant")
+	queryCmd.Flags().BoolVar(&queryProfile.Breastfeeding, "breastfeeding", false, "The patient is breastfeeding")
+	queryCmd.Flags().StringSliceVar(&queryProfile.Allergies, "allergy", nil, "Allergy or intolerance of the patient: ingredient, excipient or drug class (repeatable)")
+	queryCmd.Flags().StringSliceVar(&queryProfile.CurrentMedications, "medication", nil, "Medication the patient takes (repeatable)")
+	rootCmd.AddCommand(queryCmd)
+}
@@ -0,0 +1,46 @@
+package cli
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/briandowns/spinner"
+	configPkg "github.com/colussim/go-mysql-ai/pkg/config"
+	"github.com/colussim/go-mysql-ai/pkg/tools"
+	"github.com/spf13/cobra"
+)
+
+var reembedOptions tools.ReembedOptions
+
+var reembedCmd = &cobra.Command{
+	Use:   "reembed",
+	Short: "Move the stored vectors to another embedding model",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		config, err := loadConfig()
+		if err != nil {
+			return err
+		}
+
+		spin := spinner.New(spinner.CharSets[35], 100*time.Millisecond)
+		startTime := time.Now()
+
+		err = tools.RunReembed(config, reembedOptions, spin)
+		spin.Stop()
+		if err != nil {
+			fmt.Println()
+			return fmt.Errorf("❌ Error Re-embedding Data : %w", err)
+		}
+
+		fmt.Println()
+		configPkg.Log.Infof("✅ Re-embedding completed in %s\n", formatDuration(time.Since(startTime)))
+		return nil
+	},
+}
+
+func init() {
+	reembedCmd.Flags().StringVar(&reembedOptions.Model, "model", "", "Embedding model to move the stored vectors to")
+	reembedCmd.Flags().IntVar(&reembedOptions.Workers, "workers", 0, "Number of concurrent embedding requests (overrides import.workers)")
+	reembedCmd.MarkFlagRequired("model")
+	rootCmd.AddCommand(reembedCmd)
+}
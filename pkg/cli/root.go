@@ -0,0 +1,70 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	configPkg "github.com/colussim/go-mysql-ai/pkg/config"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var (
+	configPath string
+	logLevel   string
+)
+
+var rootCmd = &cobra.Command{
+	Use:           "go-mysql-ai",
+	Short:         "Medication recommendation chatbot backed by MySQL vector search",
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		configPkg.InitLogger()
+		level, err := logrus.ParseLevel(logLevel)
+		if err != nil {
+			return fmt.Errorf("❌ Invalid log level: %w", err)
+		}
+		configPkg.Log.SetLevel(level)
+		return nil
+	},
+}
+
+func init() {
+	defaultConfig := os.Getenv(configPkg.EnvPrefix + "_CONFIG")
+	if defaultConfig == "" {
+		defaultConfig = "config/config.json"
+	}
+
+	rootCmd.PersistentFlags().StringVar(&configPath, "config", defaultConfig, "Configuration file (or "+configPkg.EnvPrefix+"_CONFIG)")
+	rootCmd.PersistentFlags().StringVar(&logLevel, "log-level", "info", "Log level: debug, info, warn or error")
+}
+
+// Execute runs the command given on the command line
+func Execute() {
+	if err := rootCmd.Execute(); err != nil {
+		configPkg.InitLogger()
+		configPkg.Log.Errorf("%v", err)
+		os.Exit(1)
+	}
+}
+
+// loadConfig reads the --config file with its environment overrides
+func loadConfig() (*configPkg.Config, error) {
+	config, err := configPkg.LoadConfig(configPath)
+	if err != nil {
+		return nil, fmt.Errorf("❌ Error reading config file %s: %w", configPath, err)
+	}
+	configPkg.Log.Debugf("✅ Config loaded from %s: %v", configPath, config)
+	return config, nil
+}
+
+func formatDuration(duration time.Duration) string {
+
+	hours := int(duration.Hours())
+	minutes := int(duration.Minutes()) % 60
+	seconds := int(duration.Seconds()) % 60
+
+	return fmt.Sprintf("%02d:%02d:%02d", hours, minutes, seconds)
+}
@@ -0,0 +1,166 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	configPkg "github.com/colussim/go-mysql-ai/pkg/config"
+	"github.com/colussim/go-mysql-ai/pkg/embedding"
+	"github.com/colussim/go-mysql-ai/pkg/schema"
+	"github.com/colussim/go-mysql-ai/pkg/store"
+	"github.com/spf13/cobra"
+)
+
+var (
+	migrateUpSteps   int
+	migrateDownSteps int
+	migrateDimension int
+)
+
+var schemaCmd = &cobra.Command{
+	Use:   "schema",
+	Short: "Manage the database schema",
+}
+
+var schemaMigrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Apply, revert or list the schema migrations of the MySQL store",
+}
+
+var migrateUpCmd = &cobra.Command{
+	Use:   "up",
+	Short: "Create the database if needed and apply the pending migrations",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		config, err := loadMySQLConfig()
+		if err != nil || config == nil {
+			return err
+		}
+		ctx := context.Background()
+
+		// The VECTOR columns take the output dimension of the embedding model
+		dimension := migrateDimension
+		if dimension == 0 {
+			embeddings, err := embedding.New(ctx, config)
+			if err != nil {
+				return fmt.Errorf("❌ Error initializing embedding model: %w", err)
+			}
+			dimension = embeddings.Dimension
+		}
+
+		server, err := store.OpenMySQLDB(config, "")
+		if err != nil {
+			return err
+		}
+		err = schema.CreateDatabase(ctx, server, store.MySQLDatabase(config), config.MySQL.Tablespace)
+		server.Close()
+		if err != nil {
+			return err
+		}
+
+		return withMigrator(config, dimension, func(migrator *schema.Migrator) error {
+			applied, err := migrator.Up(ctx, migrateUpSteps)
+			for _, migration := range applied {
+				configPkg.Log.Infof("✅ Applied %s\n", migration)
+			}
+			if err != nil {
+				return err
+			}
+			if len(applied) == 0 {
+				configPkg.Log.Infof("✅ Schema of %s is up to date\n", store.MySQLDatabase(config))
+			}
+			return nil
+		})
+	},
+}
+
+var migrateDownCmd = &cobra.Command{
+	Use:   "down",
+	Short: "Revert the latest applied migrations, dropping their tables",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		config, err := loadMySQLConfig()
+		if err != nil || config == nil {
+			return err
+		}
+
+		return withMigrator(config, 0, func(migrator *schema.Migrator) error {
+			reverted, err := migrator.Down(context.Background(), migrateDownSteps)
+			for _, migration := range reverted {
+				configPkg.Log.Infof("✅ Reverted %s\n", migration)
+			}
+			if err != nil {
+				return err
+			}
+			if len(reverted) == 0 {
+				configPkg.Log.Infof("✅ No migration to revert\n")
+			}
+			return nil
+		})
+	},
+}
+
+var migrateStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "List the migrations and when they were applied",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		config, err := loadMySQLConfig()
+		if err != nil || config == nil {
+			return err
+		}
+
+		return withMigrator(config, 0, func(migrator *schema.Migrator) error {
+			status, err := migrator.Status(context.Background())
+			if err != nil {
+				return err
+			}
+			for _, s := range status {
+				applied := "pending"
+				if s.AppliedAt != nil {
+					applied = s.AppliedAt.Format("2006-01-02 15:04:05")
+				}
+				fmt.Printf("%-40s %s\n", s.Migration, applied)
+			}
+			return nil
+		})
+	},
+}
+
+// loadMySQLConfig loads the configuration, or returns nil when the store
+// is not MySQL: the other stores create their schema on open
+func loadMySQLConfig() (*configPkg.Config, error) {
+	config, err := loadConfig()
+	if err != nil {
+		return nil, err
+	}
+	if storeType := strings.ToLower(config.Store.Type); storeType != "" && storeType != "mysql" {
+		configPkg.Log.Infof("✅ The %s store creates its schema on open, nothing to migrate\n", config.Store.Type)
+		return nil, nil
+	}
+	return config, nil
+}
+
+func withMigrator(config *configPkg.Config, dimension int, fn func(*schema.Migrator) error) error {
+	db, err := store.OpenMySQLDB(config, store.MySQLDatabase(config))
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	return fn(schema.NewMigrator(db, schema.Params{
+		Tablespace: config.MySQL.Tablespace,
+		Dimension:  dimension,
+	}))
+}
+
+func init() {
+	migrateUpCmd.Flags().IntVar(&migrateUpSteps, "steps", 0, "Number of migrations to apply (default all)")
+	migrateUpCmd.Flags().IntVar(&migrateDimension, "dimension", 0, "Width of the VECTOR columns (default the dimension of the embedding model)")
+	migrateDownCmd.Flags().IntVar(&migrateDownSteps, "steps", 1, "Number of migrations to revert, 0 for all")
+
+	schemaMigrateCmd.AddCommand(migrateUpCmd, migrateDownCmd, migrateStatusCmd)
+	schemaCmd.AddCommand(schemaMigrateCmd)
+	rootCmd.AddCommand(schemaCmd)
+}
@@ -0,0 +1,40 @@
+package cli
+
+import (
+	"github.com/colussim/go-mysql-ai/pkg/server"
+	"github.com/spf13/cobra"
+)
+
+const defaultPort = 3001
+
+var servePort int
+
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Start the chatbot web server",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		config, err := loadConfig()
+		if err != nil {
+			return err
+		}
+		if err := server.Setup(config); err != nil {
+			return err
+		}
+		defer server.Close()
+
+		port := servePort
+		if port == 0 {
+			port = config.Chatbotport.Port
+		}
+		if port == 0 {
+			port = defaultPort
+		}
+		return server.Run(port)
+	},
+}
+
+func init() {
+	serveCmd.Flags().IntVar(&servePort, "port", 0, "Port on which the server will listen (default chatbotport.port)")
+	rootCmd.AddCommand(serveCmd)
+}
@@ -9,29 +9,93 @@ import (
 
 type Config struct {
 	MySQL struct {
-		User     string `json:"user"`
-		Password string `json:"password"`
-		Server   string `json:"server"`
-		Port     string `json:"port"`
-		TypeAuth string `json:"type_auth"`
+		User            string `json:"user"`
+		Password        string `json:"password"`
+		PasswordEnv     string `json:"password_env"`
+		PasswordFile    string `json:"password_file"`
+		PasswordCommand string `json:"password_command"`
+		Server          string `json:"server"`
+		Port            string `json:"port"`
+		TypeAuth        string `json:"type_auth"`
+		Database        string `json:"database"`
+		Tablespace      string `json:"tablespace"`
+		Socket          string `json:"socket"`
+		Charset         string `json:"charset"`
+		TLS             struct {
+			Mode       string `json:"mode"`
+			CA         string `json:"ca"`
+			Cert       string `json:"cert"`
+			Key        string `json:"key"`
+			ServerName string `json:"server_name"`
+		} `json:"tls"`
+		ConnectTimeout  int `json:"connect_timeout"`
+		ReadTimeout     int `json:"read_timeout"`
+		WriteTimeout    int `json:"write_timeout"`
+		MaxOpenConns    int `json:"max_open_conns"`
+		MaxIdleConns    int `json:"max_idle_conns"`
+		ConnMaxLifetime int `json:"conn_max_lifetime"`
 	} `json:"mysql"`
+	Store struct {
+		Type string `json:"type"`
+		Path string `json:"path"`
+	} `json:"store"`
 	Pathologie struct {
 		File string `json:"file"`
 	} `json:"pathologie"`
+	Triage struct {
+		File string `json:"file"`
+	} `json:"triage"`
 	Models struct {
 		Embedding struct {
 			Name string `json:"name"`
+			ProviderConfig
 		} `json:"embedding"`
 		Generation struct {
 			Name   string `json:"name"`
 			Prompt string `json:"prompt"`
+			ProviderConfig
 		} `json:"generation"`
 	} `json:"models"`
+	OpenFDA struct {
+		URL               string `json:"url"`
+		APIKey            string `json:"api_key"`
+		PageSize          int    `json:"page_size"`
+		MaxResults        int    `json:"max_results"`
+		RequestsPerMinute int    `json:"requests_per_minute"`
+		MaxRetries        int    `json:"max_retries"`
+		Timeout           int    `json:"timeout"`
+	} `json:"openfda"`
+	Import struct {
+		Workers    int    `json:"workers"`
+		BatchSize  int    `json:"batch_size"`
+		JournalDir string `json:"journal_dir"`
+	} `json:"import"`
+	Search struct {
+		Threshold float64 `json:"threshold"`
+		Limit     int     `json:"limit"`
+		Ranking   string  `json:"ranking"`
+	} `json:"search"`
+	Symptoms struct {
+		Threshold float64 `json:"threshold"`
+		Margin    float64 `json:"margin"`
+		Minimum   float64 `json:"minimum"`
+	} `json:"symptoms"`
+	Chat struct {
+		TokenBudget int `json:"token_budget"`
+	} `json:"chat"`
 	Chatbotport struct {
 		Port int `json:"port"`
 	} `json:"chatbotport"`
 }
 
+// ProviderConfig selects the LLM backend serving a model: "ollama" (default)
+// or "openai" for any OpenAI-compatible endpoint
+type ProviderConfig struct {
+	Provider string `json:"provider"`
+	URL      string `json:"url"`
+	APIKey   string `json:"api_key"`
+}
+
 type PathologyDetail struct {
 	Description string   `json:"description"`
 	Symptoms    []string `json:"symptoms"`
@@ -52,6 +116,8 @@ func InitLogger() {
 
 }
 
+// LoadConfig reads the configuration file, then applies the environment
+// variable overrides (see ApplyEnv)
 func LoadConfig(filename string) (*Config, error) {
 	file, err := os.ReadFile(filename)
 	if err != nil {
@@ -61,6 +127,9 @@ func LoadConfig(filename string) (*Config, error) {
 	if err := json.Unmarshal(file, &config); err != nil {
 		return nil, err
 	}
+	if err := ApplyEnv(&config); err != nil {
+		return nil, err
+	}
 	return &config, nil
 }
 
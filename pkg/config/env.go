@@ -0,0 +1,112 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// EnvPrefix starts the name of the environment variables overriding the
+// configuration file
+const EnvPrefix = "GOMYSQLAI"
+
+// ApplyEnv overrides every field of config that has an environment variable
+// set. The variable name is the path of JSON keys in upper case, e.g.
+// GOMYSQLAI_MYSQL_PASSWORD or GOMYSQLAI_MODELS_EMBEDDING_URL. Lists are
+// given as comma separated values.
+func ApplyEnv(config *Config) error {
+	return applyEnv(reflect.ValueOf(config).Elem(), EnvPrefix)
+}
+
+// EnvNames lists the environment variables read by ApplyEnv
+func EnvNames() []string {
+	var names []string
+	walkEnv(reflect.TypeOf(Config{}), EnvPrefix, func(name string, _ []int) {
+		names = append(names, name)
+	})
+	return names
+}
+
+func applyEnv(v reflect.Value, prefix string) error {
+	var err error
+	walkEnv(v.Type(), prefix, func(name string, index []int) {
+		value, ok := os.LookupEnv(name)
+		if !ok || err != nil {
+			return
+		}
+		if setErr := setField(v.FieldByIndex(index), value); setErr != nil {
+			err = fmt.Errorf("❌ Invalid value for %s: %w", name, setErr)
+		}
+	})
+	return err
+}
+
+// walkEnv calls fn with the variable name and field index of every leaf
+// field. Embedded structs share the prefix of their parent.
+func walkEnv(t reflect.Type, prefix string, fn func(name string, index []int)) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name := prefix
+		if !field.Anonymous {
+			key, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+			if key == "" || key == "-" {
+				continue
+			}
+			name += "_" + strings.ToUpper(key)
+		}
+
+		if field.Type.Kind() == reflect.Struct {
+			walkEnv(field.Type, name, func(child string, index []int) {
+				fn(child, append([]int{i}, index...))
+			})
+			continue
+		}
+		fn(name, []int{i})
+	}
+}
+
+func setField(field reflect.Value, value string) error {
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(value)
+	case reflect.Int:
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return err
+		}
+		field.SetInt(int64(n))
+	case reflect.Float64:
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return err
+		}
+		field.SetFloat(f)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		field.SetBool(b)
+	case reflect.Slice:
+		if field.Type().Elem().Kind() != reflect.String {
+			return fmt.Errorf("unsupported field type %s", field.Type())
+		}
+		// Lists are comma separated
+		var items []string
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		field.Set(reflect.ValueOf(items))
+	default:
+		return fmt.Errorf("unsupported field type %s", field.Type())
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// secretCommandTimeout bounds the run of a password_command
+const secretCommandTimeout = 30 * time.Second
+
+const redacted = "********"
+
+// MySQLPassword resolves the MySQL password from the first source set in the
+// mysql section: the output of password_command, the content of
+// password_file, the environment variable named by password_env, then
+// password itself.
+func (c *Config) MySQLPassword() (string, error) {
+	mysql := c.MySQL
+	switch {
+	case mysql.PasswordCommand != "":
+		return secretFromCommand(mysql.PasswordCommand)
+	case mysql.PasswordFile != "":
+		content, err := os.ReadFile(mysql.PasswordFile)
+		if err != nil {
+			return "", fmt.Errorf("❌ Error reading MySQL password file: %w", err)
+		}
+		return strings.TrimRight(string(content), "\r\n"), nil
+	case mysql.PasswordEnv != "":
+		password, ok := os.LookupEnv(mysql.PasswordEnv)
+		if !ok {
+			return "", fmt.Errorf("❌ MySQL password variable %s is not set", mysql.PasswordEnv)
+		}
+		return password, nil
+	default:
+		return mysql.Password, nil
+	}
+}
+
+// secretFromCommand runs a credential helper with the shell and returns its
+// output without the trailing newline
+func secretFromCommand(command string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), secretCommandTimeout)
+	defer cancel()
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "sh", "-c", command)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("❌ Error running password command: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return strings.TrimRight(stdout.String(), "\r\n"), nil
+}
+
+// Redacted returns a copy of the configuration without its secrets
+func (c Config) Redacted() Config {
+	hide := func(secret *string) {
+		if *secret != "" {
+			*secret = redacted
+		}
+	}
+	hide(&c.MySQL.Password)
+	hide(&c.Models.Embedding.APIKey)
+	hide(&c.Models.Generation.APIKey)
+	hide(&c.OpenFDA.APIKey)
+	return c
+}
+
+// String formats the configuration as JSON with its secrets redacted, so a
+// Config can be logged safely
+func (c Config) String() string {
+	content, err := json.Marshal(c.Redacted())
+	if err != nil {
+		return fmt.Sprintf("❌ Error encoding config: %v", err)
+	}
+	return string(content)
+}
+
+// GoString keeps %#v from printing the secrets
+func (c Config) GoString() string {
+	return c.String()
+}
@@ -0,0 +1,110 @@
+package embedding
+
+import (
+	"context"
+	"fmt"
+
+	configPkg "github.com/colussim/go-mysql-ai/pkg/config"
+	"github.com/colussim/go-mysql-ai/pkg/llm"
+	"github.com/colussim/go-mysql-ai/pkg/store"
+)
+
+// probeText is embedded once at startup to learn the model dimension
+const probeText = "dimension probe"
+
+// Service embeds text with the model of the "models.embedding" section. The
+// import and the chatbot share it so pathologies, medications and questions
+// always live in the same embedding space.
+type Service struct {
+	Model     string
+	Dimension int
+
+	provider llm.Provider
+}
+
+// New connects to the embedding provider and probes the output dimension
+// of the model
+func New(ctx context.Context, config *configPkg.Config) (*Service, error) {
+	if config.Models.Embedding.Name == "" {
+		return nil, fmt.Errorf("❌ No embedding model configured in models.embedding.name")
+	}
+	return NewModel(ctx, config.Models.Embedding.ProviderConfig, config.Models.Embedding.Name)
+}
+
+// NewModel is New for another model of the embedding provider
+func NewModel(ctx context.Context, providerConfig configPkg.ProviderConfig, model string) (*Service, error) {
+	provider, err := llm.New(providerConfig)
+	if err != nil {
+		return nil, fmt.Errorf("❌ Error initializing embedding provider: %w", err)
+	}
+
+	s := &Service{Model: model, provider: provider}
+	probe, err := s.EmbedText(ctx, probeText)
+	if err != nil {
+		return nil, fmt.Errorf("❌ Error probing embedding model %s: %w", s.Model, err)
+	}
+	if len(probe) == 0 {
+		return nil, fmt.Errorf("❌ Embedding model %s returned an empty vector", s.Model)
+	}
+	s.Dimension = len(probe)
+
+	return s, nil
+}
+
+// Embed returns one vector per input, in order
+func (s *Service) Embed(ctx context.Context, input []string) ([][]float64, error) {
+	embeddings, err := s.provider.Embed(ctx, s.Model, input)
+	if err != nil {
+		return nil, err
+	}
+	for _, embedding := range embeddings {
+		if s.Dimension > 0 && len(embedding) != s.Dimension {
+			return nil, fmt.Errorf("❌ Embedding model %s returned %d dimensions instead of %d", s.Model, len(embedding), s.Dimension)
+		}
+	}
+	return embeddings, nil
+}
+
+func (s *Service) EmbedText(ctx context.Context, text string) ([]float64, error) {
+	embeddings, err := s.Embed(ctx, []string{text})
+	if err != nil {
+		return nil, err
+	}
+	if len(embeddings) != 1 {
+		return nil, fmt.Errorf("❌ Expected 1 embedding, got %d", len(embeddings))
+	}
+	return embeddings[0], nil
+}
+
+// Check refuses a store whose vector columns cannot hold the vectors of the
+// model, or whose vectors were produced by another model. A store that
+// has not recorded its model yet is stamped with this one when record is
+// true (the import), and only reported otherwise (the chatbot).
+func (s *Service) Check(ctx context.Context, vectorStore store.VectorStore, record bool) error {
+	if sized, ok := vectorStore.(store.VectorSizer); ok {
+		width, err := sized.VectorDimension(ctx)
+		if err != nil {
+			return err
+		}
+		if width > 0 && width != s.Dimension {
+			return fmt.Errorf("❌ Embedding model %s produces %d dimensions but the embedding columns are VECTOR(%d): alter the columns or choose another model",
+				s.Model, s.Dimension, width)
+		}
+	}
+
+	model, dimension, err := vectorStore.EmbeddingModel(ctx)
+	if err != nil {
+		return err
+	}
+	switch {
+	case model == "" && record:
+		return vectorStore.SetEmbeddingModel(ctx, s.Model, s.Dimension)
+	case model == "":
+		configPkg.Log.Warnf("⚠️ The store does not record the model of its vectors, expecting %s", s.Model)
+		return nil
+	case model != s.Model || dimension != s.Dimension:
+		return fmt.Errorf("❌ The stored vectors were produced by %s (%d dimensions) but the configured embedding model is %s (%d dimensions): re-embed the data before switching models",
+			model, dimension, s.Model, s.Dimension)
+	}
+	return nil
+}
@@ -0,0 +1,112 @@
+package interactions
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/colussim/go-mysql-ai/pkg/store"
+)
+
+// Product is a medication checked for interactions: a retrieved label, or
+// what the user said they take
+type Product struct {
+	Name        string
+	Ingredients []string
+}
+
+// Warning is an interaction found between the ingredients of two products
+type Warning struct {
+	store.Interaction
+	Products [2]string
+}
+
+// Checker finds the ingredients of the interaction table in free text and
+// the interactions between products
+type Checker struct {
+	interactions map[[2]string]store.Interaction
+	ingredients  []string
+	patterns     map[string]*regexp.Regexp
+}
+
+// NewChecker indexes normalized interactions, as returned by the store
+func NewChecker(interactions []store.Interaction) *Checker {
+	c := &Checker{
+		interactions: make(map[[2]string]store.Interaction, len(interactions)),
+		patterns:     make(map[string]*regexp.Regexp),
+	}
+	for _, i := range interactions {
+		c.interactions[[2]string{i.IngredientA, i.IngredientB}] = i
+		for _, ingredient := range []string{i.IngredientA, i.IngredientB} {
+			if _, ok := c.patterns[ingredient]; ok {
+				continue
+			}
+			c.patterns[ingredient] = regexp.MustCompile(`(?i)\b` + regexp.QuoteMeta(ingredient) + `\b`)
+			c.ingredients = append(c.ingredients, ingredient)
+		}
+	}
+	sort.Strings(c.ingredients)
+	return c
+}
+
+// Len returns the number of known interactions
+func (c *Checker) Len() int {
+	return len(c.interactions)
+}
+
+// Ingredients returns the ingredients of the interaction table mentioned
+// in a text, in alphabetical order
+func (c *Checker) Ingredients(text string) []string {
+	var found []string
+	for _, ingredient := range c.ingredients {
+		if c.patterns[ingredient].MatchString(text) {
+			found = append(found, ingredient)
+		}
+	}
+	return found
+}
+
+// Check returns the interactions between the ingredients of different
+// products, the most severe first. The ingredients of a single product are
+// not checked against each other.
+func (c *Checker) Check(products []Product) []Warning {
+	var warnings []Warning
+	seen := make(map[[4]string]bool)
+
+	for i := range products {
+		for j := i + 1; j < len(products); j++ {
+			for _, a := range products[i].Ingredients {
+				for _, b := range products[j].Ingredients {
+					key := pair(a, b)
+					interaction, ok := c.interactions[key]
+					if !ok {
+						continue
+					}
+					// A pair of products is reported once per interaction
+					id := [4]string{key[0], key[1], products[i].Name, products[j].Name}
+					if seen[id] {
+						continue
+					}
+					seen[id] = true
+					warnings = append(warnings, Warning{
+						Interaction: interaction,
+						Products:    [2]string{products[i].Name, products[j].Name},
+					})
+				}
+			}
+		}
+	}
+
+	sort.SliceStable(warnings, func(i, j int) bool {
+		return store.SeverityRank(warnings[i].Severity) > store.SeverityRank(warnings[j].Severity)
+	})
+	return warnings
+}
+
+func pair(a, b string) [2]string {
+	a, b = strings.ToLower(a), strings.ToLower(b)
+	if b < a {
+		a, b = b, a
+	}
+	return [2]string{a, b}
+}
@@ -0,0 +1,145 @@
+package interactions
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/colussim/go-mysql-ai/pkg/store"
+)
+
+// table is a normalized interaction table, as returned by the store
+var table = []store.Interaction{
+	{IngredientA: "aspirin", IngredientB: "ibuprofen", Severity: "moderate", Description: "Ibuprofen reduces the effect of aspirin."},
+	{IngredientA: "aspirin", IngredientB: "warfarin", Severity: "major", Description: "Bleeding risk."},
+	{IngredientA: "ibuprofen", IngredientB: "warfarin", Severity: "major", Description: "Bleeding risk."},
+	{IngredientA: "acetaminophen", IngredientB: "warfarin", Severity: "minor", Description: "May increase the INR."},
+	{IngredientA: "linezolid", IngredientB: "pseudoephedrine", Severity: "contraindicated", Description: "Hypertensive crisis."},
+}
+
+func TestPair(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want [2]string
+	}{
+		{"aspirin", "ibuprofen", [2]string{"aspirin", "ibuprofen"}},
+		{"ibuprofen", "aspirin", [2]string{"aspirin", "ibuprofen"}},
+		{"Ibuprofen", "ASPIRIN", [2]string{"aspirin", "ibuprofen"}},
+		{"warfarin", "Acetaminophen", [2]string{"acetaminophen", "warfarin"}},
+		{"aspirin", "aspirin", [2]string{"aspirin", "aspirin"}},
+	}
+	for _, tt := range tests {
+		if got := pair(tt.a, tt.b); got != tt.want {
+			t.Errorf("pair(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+// summary writes a warning as "severity: product + product (a + b)"
+func summary(warnings []Warning) []string {
+	list := []string{}
+	for _, w := range warnings {
+		list = append(list, fmt.Sprintf("%s: %s + %s (%s + %s)", w.Severity, w.Products[0], w.Products[1], w.IngredientA, w.IngredientB))
+	}
+	return list
+}
+
+func TestCheck(t *testing.T) {
+	tests := []struct {
+		name     string
+		products []Product
+		want     []string
+	}{
+		{
+			name:     "no products",
+			products: nil,
+			want:     []string{},
+		},
+		{
+			name: "no interaction",
+			products: []Product{
+				{Name: "Tylenol", Ingredients: []string{"acetaminophen"}},
+				{Name: "Advil", Ingredients: []string{"ibuprofen"}},
+			},
+			want: []string{},
+		},
+		{
+			name: "ingredients in either order and case",
+			products: []Product{
+				{Name: "Advil", Ingredients: []string{"Ibuprofen"}},
+				{Name: "Bayer", Ingredients: []string{"ASPIRIN"}},
+			},
+			want: []string{"moderate: Advil + Bayer (aspirin + ibuprofen)"},
+		},
+		{
+			name: "ingredients of a single product are not checked",
+			products: []Product{
+				{Name: "Combination", Ingredients: []string{"aspirin", "ibuprofen", "warfarin"}},
+				{Name: "Tylenol", Ingredients: []string{"acetaminophen"}},
+			},
+			want: []string{"minor: Combination + Tylenol (acetaminophen + warfarin)"},
+		},
+		{
+			name: "an interaction is reported once per pair of products",
+			products: []Product{
+				{Name: "Advil", Ingredients: []string{"ibuprofen", "Ibuprofen"}},
+				{Name: "Bayer", Ingredients: []string{"aspirin", "aspirin"}},
+			},
+			want: []string{"moderate: Advil + Bayer (aspirin + ibuprofen)"},
+		},
+		{
+			name: "the same interaction between other products is reported again",
+			products: []Product{
+				{Name: "Advil", Ingredients: []string{"ibuprofen"}},
+				{Name: "Motrin", Ingredients: []string{"ibuprofen"}},
+				{Name: "Bayer", Ingredients: []string{"aspirin"}},
+			},
+			want: []string{
+				"moderate: Advil + Bayer (aspirin + ibuprofen)",
+				"moderate: Motrin + Bayer (aspirin + ibuprofen)",
+			},
+		},
+		{
+			name: "most severe first",
+			products: []Product{
+				{Name: "Tylenol", Ingredients: []string{"acetaminophen"}},
+				{Name: "Advil", Ingredients: []string{"ibuprofen"}},
+				{Name: "Sudafed", Ingredients: []string{"pseudoephedrine"}},
+				{Name: "your medications", Ingredients: []string{"warfarin", "aspirin", "linezolid"}},
+			},
+			want: []string{
+				"contraindicated: Sudafed + your medications (linezolid + pseudoephedrine)",
+				"major: Advil + your medications (ibuprofen + warfarin)",
+				"moderate: Advil + your medications (aspirin + ibuprofen)",
+				"minor: Tylenol + your medications (acetaminophen + warfarin)",
+			},
+		},
+	}
+
+	checker := NewChecker(table)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := summary(checker.Check(tt.products))
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("Check() =\n%q\nwant\n%q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIngredients(t *testing.T) {
+	checker := NewChecker(table)
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{"I take Warfarin every day", []string{"warfarin"}},
+		{"aspirin and ibuprofen", []string{"aspirin", "ibuprofen"}},
+		{"nothing relevant", nil},
+		{"aspirinated", nil},
+	}
+	for _, tt := range tests {
+		if got := checker.Ingredients(tt.text); fmt.Sprint(got) != fmt.Sprint(tt.want) {
+			t.Errorf("Ingredients(%q) = %v, want %v", tt.text, got, tt.want)
+		}
+	}
+}
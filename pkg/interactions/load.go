@@ -0,0 +1,92 @@
+package interactions
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/colussim/go-mysql-ai/pkg/store"
+)
+
+// Columns of a CSV interaction file, given in its header row in any order.
+// source is optional.
+var csvColumns = []string{"ingredient_a", "ingredient_b", "severity", "description", "source"}
+
+// Load reads the interactions of a CSV file (.csv) or of a JSON array
+// (any other extension) with the same fields. Every interaction is
+// normalized and checked.
+func Load(path string) ([]*store.Interaction, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("❌ Error opening interaction file: %w", err)
+	}
+	defer file.Close()
+
+	var interactions []*store.Interaction
+	if strings.EqualFold(filepath.Ext(path), ".csv") {
+		interactions, err = readCSV(file)
+	} else {
+		err = json.NewDecoder(file).Decode(&interactions)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("❌ Error reading %s: %w", path, err)
+	}
+
+	for n, i := range interactions {
+		if err := store.NormalizeInteraction(i); err != nil {
+			return nil, fmt.Errorf("❌ Interaction %d of %s: %w", n+1, path, err)
+		}
+	}
+	return interactions, nil
+}
+
+func readCSV(r io.Reader) ([]*store.Interaction, error) {
+	reader := csv.NewReader(r)
+	reader.Comment = '#'
+	reader.TrimLeadingSpace = true
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("❌ Error reading the header: %w", err)
+	}
+	index := make(map[string]int)
+	for i, name := range header {
+		index[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, name := range csvColumns[:4] {
+		if _, ok := index[name]; !ok {
+			return nil, fmt.Errorf("❌ Missing column %s, expected %s", name, strings.Join(csvColumns, ","))
+		}
+	}
+
+	field := func(record []string, name string) string {
+		if i, ok := index[name]; ok && i < len(record) {
+			return record[i]
+		}
+		return ""
+	}
+
+	var interactions []*store.Interaction
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return interactions, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		interactions = append(interactions, &store.Interaction{
+			IngredientA: field(record, "ingredient_a"),
+			IngredientB: field(record, "ingredient_b"),
+			Severity:    field(record, "severity"),
+			Description: field(record, "description"),
+			Source:      field(record, "source"),
+		})
+	}
+}
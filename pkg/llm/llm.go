@@ -0,0 +1,37 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	configPkg "github.com/colussim/go-mysql-ai/pkg/config"
+)
+
+type Message struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+// ChunkFunc receives each piece of a streamed chat answer
+type ChunkFunc func(chunk string) error
+
+// Provider generates chat completions and embeddings
+type Provider interface {
+	// Chat streams the answer of the model to fn, chunk by chunk
+	Chat(ctx context.Context, model string, messages []Message, fn ChunkFunc) error
+	// Embed returns one vector per input, in the same order
+	Embed(ctx context.Context, model string, input []string) ([][]float64, error)
+}
+
+// New returns the provider configured for a model
+func New(config configPkg.ProviderConfig) (Provider, error) {
+	switch strings.ToLower(config.Provider) {
+	case "", "ollama":
+		return NewOllama(config.URL)
+	case "openai":
+		return NewOpenAI(config.URL, config.APIKey)
+	default:
+		return nil, fmt.Errorf("❌ Unknown LLM provider: %s", config.Provider)
+	}
+}
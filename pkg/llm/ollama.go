@@ -0,0 +1,95 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"sync/atomic"
+
+	"github.com/ollama/ollama/api"
+)
+
+type Ollama struct {
+	client *api.Client
+
+	// legacyEmbed is set once the server has no /api/embed endpoint
+	legacyEmbed atomic.Bool
+}
+
+// NewOllama connects to an Ollama server. When host is empty, OLLAMA_HOST
+// or the default local server is used.
+func NewOllama(host string) (*Ollama, error) {
+	if host == "" {
+		host = os.Getenv("OLLAMA_HOST")
+	}
+	if host == "" {
+		host = "http://localhost:11434"
+	}
+	parsedURL, err := url.Parse(host)
+	if err != nil {
+		return nil, fmt.Errorf("❌ Invalid Ollama host URL: %w", err)
+	}
+
+	return &Ollama{client: api.NewClient(parsedURL, http.DefaultClient)}, nil
+}
+
+func (o *Ollama) Chat(ctx context.Context, model string, messages []Message, fn ChunkFunc) error {
+	chatRequest := api.ChatRequest{
+		Model:  model,
+		Stream: func(b bool) *bool { return &b }(true),
+	}
+	for _, m := range messages {
+		chatRequest.Messages = append(chatRequest.Messages, api.Message{Role: m.Role, Content: m.Content})
+	}
+
+	err := o.client.Chat(ctx, &chatRequest, func(resp api.ChatResponse) error {
+		return fn(resp.Message.Content)
+	})
+	if err != nil {
+		return fmt.Errorf("❌ Error calling Ollama API: %w", err)
+	}
+	return nil
+}
+
+// Embed sends the whole input in a single /api/embed request. Servers
+// older than that endpoint are called once per input on /api/embeddings.
+func (o *Ollama) Embed(ctx context.Context, model string, input []string) ([][]float64, error) {
+	if !o.legacyEmbed.Load() {
+		resp, err := o.client.Embed(ctx, &api.EmbedRequest{Model: model, Input: input})
+		var statusErr api.StatusError
+		switch {
+		case errors.As(err, &statusErr) && statusErr.StatusCode == http.StatusNotFound && statusErr.ErrorMessage == "404 page not found":
+			o.legacyEmbed.Store(true)
+		case err != nil:
+			return nil, fmt.Errorf("❌ Error generating embedding: %w", err)
+		case len(resp.Embeddings) != len(input):
+			return nil, fmt.Errorf("❌ Expected %d embeddings, got %d", len(input), len(resp.Embeddings))
+		default:
+			embeddings := make([][]float64, len(resp.Embeddings))
+			for i, embedding := range resp.Embeddings {
+				embeddings[i] = make([]float64, len(embedding))
+				for j, v := range embedding {
+					embeddings[i][j] = float64(v)
+				}
+			}
+			return embeddings, nil
+		}
+	}
+
+	embeddings := make([][]float64, 0, len(input))
+	for _, text := range input {
+		req := &api.EmbeddingRequest{
+			Model:  model,
+			Prompt: text,
+		}
+		resp, err := o.client.Embeddings(ctx, req)
+		if err != nil {
+			return nil, fmt.Errorf("❌ Error generating embedding: %w", err)
+		}
+		embeddings = append(embeddings, resp.Embedding)
+	}
+	return embeddings, nil
+}
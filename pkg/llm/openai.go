@@ -0,0 +1,158 @@
+package llm
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+)
+
+// OpenAI talks to any server implementing the OpenAI chat completions and
+// embeddings API (llama.cpp server, vLLM, LocalAI, gateways...)
+type OpenAI struct {
+	baseURL string
+	apiKey  string
+	client  *http.Client
+}
+
+type openAIChatRequest struct {
+	Model    string    `json:"model"`
+	Messages []Message `json:"messages"`
+	Stream   bool      `json:"stream"`
+}
+
+type openAIChatChunk struct {
+	Choices []struct {
+		Delta struct {
+			Content string `json:"content"`
+		} `json:"delta"`
+	} `json:"choices"`
+}
+
+type openAIEmbeddingRequest struct {
+	Model string   `json:"model"`
+	Input []string `json:"input"`
+}
+
+type openAIEmbeddingResponse struct {
+	Data []struct {
+		Index     int       `json:"index"`
+		Embedding []float64 `json:"embedding"`
+	} `json:"data"`
+}
+
+// NewOpenAI uses baseURL as the API root, including the version path
+// (e.g. http://localhost:8080/v1). When apiKey is empty, OPENAI_API_KEY is used.
+func NewOpenAI(baseURL string, apiKey string) (*OpenAI, error) {
+	if baseURL == "" {
+		baseURL = "http://localhost:8080/v1"
+	}
+	if apiKey == "" {
+		apiKey = os.Getenv("OPENAI_API_KEY")
+	}
+
+	return &OpenAI{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		apiKey:  apiKey,
+		client:  http.DefaultClient,
+	}, nil
+}
+
+func (o *OpenAI) Chat(ctx context.Context, model string, messages []Message, fn ChunkFunc) error {
+	resp, err := o.post(ctx, "/chat/completions", openAIChatRequest{Model: model, Messages: messages, Stream: true})
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	// The answer is streamed as server-sent events: "data: {chunk}" lines
+	// terminated by "data: [DONE]"
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if !strings.HasPrefix(line, "data:") {
+			continue
+		}
+		data := strings.TrimSpace(strings.TrimPrefix(line, "data:"))
+		if data == "[DONE]" {
+			return nil
+		}
+
+		var chunk openAIChatChunk
+		if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+			return fmt.Errorf("❌ Error decoding chat chunk: %w", err)
+		}
+		for _, choice := range chunk.Choices {
+			if choice.Delta.Content == "" {
+				continue
+			}
+			if err := fn(choice.Delta.Content); err != nil {
+				return err
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("❌ Error reading chat stream: %w", err)
+	}
+	return nil
+}
+
+func (o *OpenAI) Embed(ctx context.Context, model string, input []string) ([][]float64, error) {
+	resp, err := o.post(ctx, "/embeddings", openAIEmbeddingRequest{Model: model, Input: input})
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var data openAIEmbeddingResponse
+	if err := json.NewDecoder(resp.Body).Decode(&data); err != nil {
+		return nil, fmt.Errorf("❌ Error decoding embedding response: %w", err)
+	}
+	if len(data.Data) != len(input) {
+		return nil, fmt.Errorf("❌ Expected %d embeddings, got %d", len(input), len(data.Data))
+	}
+
+	sort.Slice(data.Data, func(i, j int) bool {
+		return data.Data[i].Index < data.Data[j].Index
+	})
+	embeddings := make([][]float64, len(data.Data))
+	for i, d := range data.Data {
+		embeddings[i] = d.Embedding
+	}
+	return embeddings, nil
+}
+
+// post sends a JSON request and checks the status of the response
+func (o *OpenAI) post(ctx context.Context, path string, payload any) (*http.Response, error) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("❌ Error encoding request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, o.baseURL+path, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("❌ Error creating request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if o.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+o.apiKey)
+	}
+
+	resp, err := o.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("❌ Error calling OpenAI-compatible API: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		resp.Body.Close()
+		return nil, fmt.Errorf("❌ API returned status %d: %s", resp.StatusCode, string(message))
+	}
+	return resp, nil
+}
@@ -0,0 +1,203 @@
+package openfda
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// MatchBulkLabels reads the OpenFDA drug label bulk files found at path and
+// returns, for each pathology, the labels the live search would select:
+// labels with a brand name whose indications contain every word of the
+// pathology. path is a drug-label-000N-of-000M.json.zip partition, an
+// extracted .json partition or a directory containing them.
+func MatchBulkLabels(path string, pathologies []string) (map[string]Response, error) {
+	terms := make(map[string][]string, len(pathologies))
+	for _, pathology := range pathologies {
+		terms[pathology] = words(pathology)
+	}
+
+	matches := make(map[string]Response, len(pathologies))
+	err := ReadBulkLabels(path, func(label Label) error {
+		if len(label.OpenFDA.BrandName) == 0 {
+			return nil
+		}
+		indications := make(map[string]bool)
+		for _, w := range words(strings.Join(label.IndicationsAndUsage, " ")) {
+			indications[w] = true
+		}
+
+		for pathology, required := range terms {
+			if containsAll(indications, required) {
+				data := matches[pathology]
+				data.Results = append(data.Results, label)
+				matches[pathology] = data
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return matches, nil
+}
+
+// ReadBulkLabels calls fn for every label of the bulk files found at path.
+// Files are decoded one label at a time and never loaded in memory.
+func ReadBulkLabels(path string, fn func(Label) error) error {
+	files, err := bulkFiles(path)
+	if err != nil {
+		return err
+	}
+
+	for _, file := range files {
+		if strings.HasSuffix(file, ".zip") {
+			err = readBulkZip(file, fn)
+		} else {
+			err = readBulkJSON(file, fn)
+		}
+		if err != nil {
+			return fmt.Errorf("❌ Error reading bulk file %s: %w", file, err)
+		}
+	}
+	return nil
+}
+
+// bulkFiles lists the partitions to read, in name order
+func bulkFiles(path string) ([]string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("❌ Error opening bulk path: %w", err)
+	}
+	if !info.IsDir() {
+		return []string{path}, nil
+	}
+
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return nil, fmt.Errorf("❌ Error reading bulk directory: %w", err)
+	}
+	var files []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if !entry.IsDir() && (strings.HasSuffix(name, ".json.zip") || strings.HasSuffix(name, ".json")) {
+			files = append(files, filepath.Join(path, name))
+		}
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("❌ No .json or .json.zip file found in %s", path)
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+func readBulkZip(path string, fn func(Label) error) error {
+	archive, err := zip.OpenReader(path)
+	if err != nil {
+		return err
+	}
+	defer archive.Close()
+
+	for _, entry := range archive.File {
+		if !strings.HasSuffix(entry.Name, ".json") {
+			continue
+		}
+		r, err := entry.Open()
+		if err != nil {
+			return err
+		}
+		err = decodeLabels(r, fn)
+		r.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func readBulkJSON(path string, fn func(Label) error) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	return decodeLabels(file, fn)
+}
+
+// decodeLabels walks a {"meta": {...}, "results": [...]} document and
+// decodes the results one by one
+func decodeLabels(r io.Reader, fn func(Label) error) error {
+	decoder := json.NewDecoder(r)
+
+	if err := expectDelim(decoder, '{'); err != nil {
+		return err
+	}
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return err
+		}
+		if token != "results" {
+			// Skip the value of any other key
+			var skip json.RawMessage
+			if err := decoder.Decode(&skip); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if err := expectDelim(decoder, '['); err != nil {
+			return err
+		}
+		for decoder.More() {
+			var label Label
+			if err := decoder.Decode(&label); err != nil {
+				return err
+			}
+			if err := fn(label); err != nil {
+				return err
+			}
+		}
+		if err := expectDelim(decoder, ']'); err != nil {
+			return err
+		}
+	}
+	return expectDelim(decoder, '}')
+}
+
+func expectDelim(decoder *json.Decoder, delim json.Delim) error {
+	token, err := decoder.Token()
+	if err != nil {
+		return err
+	}
+	if token != delim {
+		return fmt.Errorf("unexpected JSON token %v, expected %v", token, delim)
+	}
+	return nil
+}
+
+// words splits a text into lower case words
+func words(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+}
+
+func containsAll(set map[string]bool, words []string) bool {
+	if len(words) == 0 {
+		return false
+	}
+	for _, w := range words {
+		if !set[w] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,212 @@
+package openfda
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	DefaultURL               = "https://api.fda.gov/drug/label.json"
+	DefaultPageSize          = 100
+	DefaultMaxResults        = 1000
+	DefaultRequestsPerMinute = 240
+	DefaultMaxRetries        = 5
+	DefaultTimeout           = 30 * time.Second
+
+	// The API refuses pages above 1000 results and skip values above 25000
+	maxPageSize = 1000
+	maxSkip     = 25000
+
+	maxBackoff = 60 * time.Second
+)
+
+// Options configures a Client. Zero values select the defaults.
+type Options struct {
+	URL               string
+	APIKey            string
+	PageSize          int
+	MaxResults        int
+	RequestsPerMinute int
+	MaxRetries        int
+	Timeout           time.Duration
+	HTTPClient        *http.Client
+}
+
+// Client searches the OpenFDA drug label endpoint. It pages through the
+// results, stays under the rate limit and retries throttled or failed
+// requests with an exponential backoff.
+type Client struct {
+	url        string
+	apiKey     string
+	pageSize   int
+	maxResults int
+	maxRetries int
+	http       *http.Client
+	limiter    *limiter
+}
+
+// NewClient returns a client for options.URL (the drug label endpoint).
+// When options.APIKey is empty, OPENFDA_API_KEY is used.
+func NewClient(options Options) *Client {
+	if options.URL == "" {
+		options.URL = DefaultURL
+	}
+	if options.APIKey == "" {
+		options.APIKey = os.Getenv("OPENFDA_API_KEY")
+	}
+	if options.PageSize <= 0 {
+		options.PageSize = DefaultPageSize
+	}
+	options.PageSize = min(options.PageSize, maxPageSize)
+	if options.MaxResults <= 0 {
+		options.MaxResults = DefaultMaxResults
+	}
+	if options.RequestsPerMinute <= 0 {
+		options.RequestsPerMinute = DefaultRequestsPerMinute
+	}
+	if options.MaxRetries < 0 {
+		options.MaxRetries = 0
+	} else if options.MaxRetries == 0 {
+		options.MaxRetries = DefaultMaxRetries
+	}
+	if options.Timeout <= 0 {
+		options.Timeout = DefaultTimeout
+	}
+	if options.HTTPClient == nil {
+		options.HTTPClient = &http.Client{Timeout: options.Timeout}
+	}
+
+	return &Client{
+		url:        options.URL,
+		apiKey:     options.APIKey,
+		pageSize:   options.PageSize,
+		maxResults: options.MaxResults,
+		maxRetries: options.MaxRetries,
+		http:       options.HTTPClient,
+		limiter:    newLimiter(options.RequestsPerMinute),
+	}
+}
+
+// SearchLabels returns the labels with a brand name whose indications match
+// pathology, up to the configured maximum number of results
+func (c *Client) SearchLabels(ctx context.Context, pathology string) (Response, error) {
+	search := "indications_and_usage:" + url.QueryEscape(pathology) + "+AND+_exists_:openfda.brand_name"
+
+	var data Response
+	for skip := 0; len(data.Results) < c.maxResults && skip <= maxSkip; {
+		limit := min(c.pageSize, c.maxResults-len(data.Results))
+
+		page, err := c.fetchPage(ctx, search, skip, limit)
+		if err != nil {
+			return data, err
+		}
+		data.Meta = page.Meta
+		data.Results = append(data.Results, page.Results...)
+
+		skip += len(page.Results)
+		if len(page.Results) == 0 || skip >= page.Meta.Results.Total {
+			break
+		}
+	}
+	return data, nil
+}
+
+// errNotFound is the API answer to a search without results
+var errNotFound = errors.New("no matches found")
+
+// fetchPage gets one page of results, retrying on throttling and server errors
+func (c *Client) fetchPage(ctx context.Context, search string, skip, limit int) (Response, error) {
+	query := "search=" + search + "&limit=" + strconv.Itoa(limit) + "&skip=" + strconv.Itoa(skip)
+	if c.apiKey != "" {
+		query += "&api_key=" + url.QueryEscape(c.apiKey)
+	}
+
+	for attempt := 0; ; attempt++ {
+		if err := c.limiter.Wait(ctx); err != nil {
+			return Response{}, err
+		}
+
+		data, retryAfter, err := c.get(ctx, c.url+"?"+query)
+		if errors.Is(err, errNotFound) {
+			return Response{}, nil
+		}
+		if err == nil || retryAfter < 0 || attempt >= c.maxRetries {
+			return data, err
+		}
+
+		// Exponential backoff unless the server said how long to wait
+		delay := retryAfter
+		if delay == 0 {
+			delay = min(time.Second<<attempt, maxBackoff)
+		}
+		if err := sleep(ctx, delay); err != nil {
+			return Response{}, err
+		}
+	}
+}
+
+// get performs a single request. retryAfter is negative when the error is
+// not worth retrying, 0 when the backoff should be computed by the caller.
+func (c *Client) get(ctx context.Context, target string) (data Response, retryAfter time.Duration, err error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return data, -1, fmt.Errorf("❌ Error creating request: %w", err)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return data, -1, ctx.Err()
+		}
+		return data, 0, fmt.Errorf("❌ Error fetching medications: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusOK:
+	case resp.StatusCode == http.StatusNotFound:
+		return data, -1, errNotFound
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return data, parseRetryAfter(resp.Header.Get("Retry-After")), fmt.Errorf("❌ API returned status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	default:
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return data, -1, fmt.Errorf("❌ API returned status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&data); err != nil {
+		return data, 0, fmt.Errorf("❌ Error unmarshalling response: %w", err)
+	}
+	return data, 0, nil
+}
+
+// parseRetryAfter reads a Retry-After header given in seconds, 0 when absent
+func parseRetryAfter(value string) time.Duration {
+	seconds, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil || seconds <= 0 {
+		return 0
+	}
+	return min(time.Duration(seconds)*time.Second, maxBackoff)
+}
+
+// sleep waits for d, returning early when ctx is done
+var sleep = func(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,218 @@
+package openfda
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeAPI serves total labels, page by page like the drug label endpoint,
+// after answering the first requests with the given failures
+type fakeAPI struct {
+	mu       sync.Mutex
+	total    int
+	failures []int
+	requests []*http.Request
+}
+
+func (f *fakeAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	f.requests = append(f.requests, r)
+	var status int
+	if len(f.failures) > 0 {
+		status, f.failures = f.failures[0], f.failures[1:]
+	}
+	f.mu.Unlock()
+
+	switch status {
+	case 0:
+	case http.StatusTooManyRequests:
+		w.Header().Set("Retry-After", "7")
+		http.Error(w, "slow down", status)
+		return
+	default:
+		http.Error(w, "failure", status)
+		return
+	}
+
+	if f.total == 0 {
+		http.Error(w, `{"error":{"code":"NOT_FOUND","message":"No matches found!"}}`, http.StatusNotFound)
+		return
+	}
+	skip, _ := strconv.Atoi(r.URL.Query().Get("skip"))
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	var page Response
+	page.Meta.Results.Skip, page.Meta.Results.Limit, page.Meta.Results.Total = skip, limit, f.total
+	for i := skip; i < min(skip+limit, f.total); i++ {
+		var label Label
+		label.ID = fmt.Sprintf("label-%d", i)
+		page.Results = append(page.Results, label)
+	}
+	json.NewEncoder(w).Encode(page)
+}
+
+// recordSleeps replaces the waits of the client with a record of them
+func recordSleeps(t *testing.T) *[]time.Duration {
+	var delays []time.Duration
+	saved := sleep
+	sleep = func(ctx context.Context, d time.Duration) error {
+		delays = append(delays, d)
+		return ctx.Err()
+	}
+	t.Cleanup(func() { sleep = saved })
+	return &delays
+}
+
+func newTestClient(t *testing.T, api *fakeAPI, options Options) *Client {
+	server := httptest.NewServer(api)
+	t.Cleanup(server.Close)
+	options.URL = server.URL
+	if options.APIKey == "" {
+		t.Setenv("OPENFDA_API_KEY", "")
+	}
+	options.RequestsPerMinute = 600000
+	return NewClient(options)
+}
+
+func TestSearchLabelsPaging(t *testing.T) {
+	tests := []struct {
+		name       string
+		total      int
+		pageSize   int
+		maxResults int
+		wantSkips  []string
+		wantLimits []string
+		wantIDs    int
+	}{
+		{"single page", 3, 10, 100, []string{"0"}, []string{"10"}, 3},
+		{"several pages", 25, 10, 100, []string{"0", "10", "20"}, []string{"10", "10", "10"}, 25},
+		{"capped by max results", 25, 10, 15, []string{"0", "10"}, []string{"10", "5"}, 15},
+		{"max results under the page size", 25, 10, 4, []string{"0"}, []string{"4"}, 4},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recordSleeps(t)
+			api := &fakeAPI{total: tt.total}
+			client := newTestClient(t, api, Options{PageSize: tt.pageSize, MaxResults: tt.maxResults})
+
+			data, err := client.SearchLabels(context.Background(), "headache")
+			if err != nil {
+				t.Fatalf("SearchLabels: %v", err)
+			}
+			if len(data.Results) != tt.wantIDs {
+				t.Errorf("got %d labels, want %d", len(data.Results), tt.wantIDs)
+			}
+			for i, label := range data.Results {
+				if want := fmt.Sprintf("label-%d", i); label.ID != want {
+					t.Errorf("label %d is %s, want %s", i, label.ID, want)
+				}
+			}
+			if len(api.requests) != len(tt.wantSkips) {
+				t.Fatalf("got %d requests, want %d", len(api.requests), len(tt.wantSkips))
+			}
+			for i, r := range api.requests {
+				if skip := r.URL.Query().Get("skip"); skip != tt.wantSkips[i] {
+					t.Errorf("request %d: skip=%s, want %s", i, skip, tt.wantSkips[i])
+				}
+				if limit := r.URL.Query().Get("limit"); limit != tt.wantLimits[i] {
+					t.Errorf("request %d: limit=%s, want %s", i, limit, tt.wantLimits[i])
+				}
+			}
+		})
+	}
+}
+
+func TestSearchLabelsRetries(t *testing.T) {
+	tests := []struct {
+		name         string
+		failures     []int
+		maxRetries   int
+		wantErr      bool
+		wantRequests int
+		wantDelays   []time.Duration
+	}{
+		{"throttled with Retry-After", []int{429}, 3, false, 2, []time.Duration{7 * time.Second}},
+		{"server errors back off", []int{500, 503}, 3, false, 3, []time.Duration{time.Second, 2 * time.Second}},
+		{"gives up after max retries", []int{502, 502, 502, 502}, 2, true, 3, []time.Duration{time.Second, 2 * time.Second}},
+		{"no retries", []int{500}, -1, true, 1, nil},
+		{"client errors are not retried", []int{400}, 3, true, 1, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			delays := recordSleeps(t)
+			api := &fakeAPI{total: 2, failures: tt.failures}
+			client := newTestClient(t, api, Options{MaxRetries: tt.maxRetries})
+
+			data, err := client.SearchLabels(context.Background(), "fever")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("SearchLabels error = %v, want error %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && len(data.Results) != 2 {
+				t.Errorf("got %d labels, want 2", len(data.Results))
+			}
+			if len(api.requests) != tt.wantRequests {
+				t.Errorf("got %d requests, want %d", len(api.requests), tt.wantRequests)
+			}
+			if fmt.Sprint(*delays) != fmt.Sprint(tt.wantDelays) {
+				t.Errorf("waited %v, want %v", *delays, tt.wantDelays)
+			}
+		})
+	}
+}
+
+func TestSearchLabelsAPIKey(t *testing.T) {
+	tests := []struct {
+		name    string
+		option  string
+		env     string
+		wantKey string
+	}{
+		{"from the options", "option-key", "env-key", "option-key"},
+		{"from the environment", "", "env-key", "env-key"},
+		{"none", "", "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recordSleeps(t)
+			t.Setenv("OPENFDA_API_KEY", tt.env)
+			api := &fakeAPI{total: 1}
+			server := httptest.NewServer(api)
+			defer server.Close()
+			client := NewClient(Options{URL: server.URL, APIKey: tt.option})
+
+			if _, err := client.SearchLabels(context.Background(), "cough"); err != nil {
+				t.Fatalf("SearchLabels: %v", err)
+			}
+			query := api.requests[0].URL.Query()
+			if key := query.Get("api_key"); key != tt.wantKey {
+				t.Errorf("api_key=%q, want %q", key, tt.wantKey)
+			}
+			if _, ok := query["api_key"]; ok != (tt.wantKey != "") {
+				t.Errorf("api_key sent: %v, want %v", ok, tt.wantKey != "")
+			}
+		})
+	}
+}
+
+func TestSearchLabelsNotFound(t *testing.T) {
+	recordSleeps(t)
+	api := &fakeAPI{}
+	client := newTestClient(t, api, Options{})
+
+	data, err := client.SearchLabels(context.Background(), "unknown pathology")
+	if err != nil {
+		t.Fatalf("SearchLabels: %v", err)
+	}
+	if len(data.Results) != 0 {
+		t.Errorf("got %d labels, want none", len(data.Results))
+	}
+	if len(api.requests) != 1 {
+		t.Errorf("got %d requests, want 1: a search without matches is not retried", len(api.requests))
+	}
+}
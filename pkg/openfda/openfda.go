@@ -0,0 +1,34 @@
+package openfda
+
+// Label is a drug label as returned by the OpenFDA drug label endpoint and
+// found in its bulk download files
+type Label struct {
+	OpenFDA struct {
+		BrandName        []string `json:"brand_name"`
+		ActiveIngredient []string `json:"active_ingredient"`
+		SubstanceName    []string `json:"substance_name"`
+	} `json:"openfda"`
+	SetID                             string   `json:"set_id"`
+	ID                                string   `json:"id"`
+	Version                           string   `json:"version"`
+	InactiveIngredient                []string `json:"inactive_ingredient"`
+	IndicationsAndUsage               []string `json:"indications_and_usage"`
+	Purpose                           []string `json:"purpose"`
+	KeepOutOfReachOfChildren          []string `json:"keep_out_of_reach_of_children"`
+	Warnings                          []string `json:"warnings"`
+	SPLProductDataElements            []string `json:"spl_product_data_elements"`
+	DosageAndAdministration           []string `json:"dosage_and_administration"`
+	PregnancyOrBreastFeeding          []string `json:"pregnancy_or_breast_feeding"`
+	PackageLabelPRincipalDisplayPanel []string `json:"package_label_principal_display_panel"`
+}
+
+type Response struct {
+	Meta struct {
+		Results struct {
+			Skip  int `json:"skip"`
+			Limit int `json:"limit"`
+			Total int `json:"total"`
+		} `json:"results"`
+	} `json:"meta"`
+	Results []Label `json:"results"`
+}
@@ -0,0 +1,290 @@
+package sanitize
+
+import (
+	"html"
+	"net/url"
+	"strings"
+)
+
+// Policy is an allowlist of HTML elements and of their attributes. Every
+// other element is removed, its text kept, except for the elements whose
+// content is code or markup (script, style...), removed with their content.
+type Policy struct {
+	elements map[string]map[string]bool
+}
+
+// Markdown allows the elements produced by the markdown renderer
+var Markdown = NewPolicy().
+	AllowElements("p", "br", "hr", "h1", "h2", "h3", "h4", "h5", "h6",
+		"strong", "b", "em", "i", "del", "s", "code", "pre", "blockquote",
+		"ul", "ol", "li", "dl", "dt", "dd", "sup", "sub",
+		"table", "thead", "tbody", "tfoot", "tr", "th", "td").
+	AllowAttributes("a", "href", "title").
+	AllowAttributes("ol", "start").
+	AllowAttributes("th", "align").
+	AllowAttributes("td", "align")
+
+// Elements removed with their content
+var rawTextElements = map[string]bool{
+	"script":   true,
+	"style":    true,
+	"iframe":   true,
+	"object":   true,
+	"embed":    true,
+	"template": true,
+	"textarea": true,
+	"noscript": true,
+	"svg":      true,
+	"math":     true,
+	"title":    true,
+	"xmp":      true,
+}
+
+// Attributes holding a URL, restricted to safeSchemes
+var urlAttributes = map[string]bool{
+	"href": true,
+	"src":  true,
+	"cite": true,
+}
+
+var safeSchemes = map[string]bool{
+	"http":   true,
+	"https":  true,
+	"mailto": true,
+}
+
+// Elements without a closing tag
+var voidElements = map[string]bool{
+	"br": true,
+	"hr": true,
+}
+
+func NewPolicy() *Policy {
+	return &Policy{elements: make(map[string]map[string]bool)}
+}
+
+// AllowElements allows elements without attributes
+func (p *Policy) AllowElements(names ...string) *Policy {
+	for _, name := range names {
+		if p.elements[name] == nil {
+			p.elements[name] = make(map[string]bool)
+		}
+	}
+	return p
+}
+
+// AllowAttributes allows an element with the given attributes
+func (p *Policy) AllowAttributes(element string, attributes ...string) *Policy {
+	p.AllowElements(element)
+	for _, attribute := range attributes {
+		p.elements[element][attribute] = true
+	}
+	return p
+}
+
+// Sanitize returns input with only the allowed elements and attributes.
+// Text is re-escaped, comments and declarations are removed, and the
+// links open no referrer.
+func (p *Policy) Sanitize(input string) string {
+	var out strings.Builder
+	skip := "" // element whose content is being removed
+
+	for len(input) > 0 {
+		i := strings.IndexByte(input, '<')
+		if i < 0 {
+			i = len(input)
+		}
+		if skip == "" {
+			out.WriteString(escapeText(input[:i]))
+		}
+		input = input[i:]
+		if input == "" {
+			break
+		}
+
+		// Comments, declarations and processing instructions
+		if strings.HasPrefix(input, "<!--") {
+			end := strings.Index(input[4:], "-->")
+			if end < 0 {
+				break
+			}
+			input = input[4+end+3:]
+			continue
+		}
+		if strings.HasPrefix(input, "<!") || strings.HasPrefix(input, "<?") {
+			end := strings.IndexByte(input, '>')
+			if end < 0 {
+				break
+			}
+			input = input[end+1:]
+			continue
+		}
+
+		t, rest, ok := parseTag(input)
+		if !ok {
+			if skip == "" {
+				out.WriteString("&lt;")
+			}
+			input = input[1:]
+			continue
+		}
+		input = rest
+
+		if skip != "" {
+			if t.closing && t.name == skip {
+				skip = ""
+			}
+			continue
+		}
+		if rawTextElements[t.name] {
+			if !t.closing && !t.selfClosing {
+				skip = t.name
+			}
+			continue
+		}
+		attributes, ok := p.elements[t.name]
+		if !ok {
+			continue
+		}
+		p.writeTag(&out, t, attributes)
+	}
+	return out.String()
+}
+
+func (p *Policy) writeTag(out *strings.Builder, t tag, allowed map[string]bool) {
+	if t.closing {
+		if !voidElements[t.name] {
+			out.WriteString("</" + t.name + ">")
+		}
+		return
+	}
+
+	out.WriteString("<" + t.name)
+	seen := make(map[string]bool)
+	for _, attribute := range t.attributes {
+		// Browsers keep the first of duplicate attributes
+		if !allowed[attribute.name] || seen[attribute.name] {
+			continue
+		}
+		seen[attribute.name] = true
+		value := html.UnescapeString(attribute.value)
+		if urlAttributes[attribute.name] && !safeURL(value) {
+			continue
+		}
+		out.WriteString(" " + attribute.name + `="` + html.EscapeString(value) + `"`)
+	}
+	if t.name == "a" {
+		out.WriteString(` rel="nofollow noopener noreferrer"`)
+	}
+	out.WriteString(">")
+}
+
+// escapeText escapes text, keeping the entities it already contains
+func escapeText(text string) string {
+	return html.EscapeString(html.UnescapeString(text))
+}
+
+// safeURL accepts relative URLs and the URLs of safeSchemes
+func safeURL(value string) bool {
+	// Browsers ignore whitespace and control characters in the scheme
+	cleaned := strings.Map(func(r rune) rune {
+		if r <= ' ' || r == 0x7f {
+			return -1
+		}
+		return r
+	}, value)
+
+	u, err := url.Parse(cleaned)
+	if err != nil {
+		return false
+	}
+	return u.Scheme == "" || safeSchemes[strings.ToLower(u.Scheme)]
+}
+
+type attribute struct {
+	name  string
+	value string
+}
+
+type tag struct {
+	name        string
+	closing     bool
+	selfClosing bool
+	attributes  []attribute
+}
+
+// parseTag reads the tag starting input, and returns the input after it. ok
+// is false when input does not start with a complete tag.
+func parseTag(input string) (t tag, rest string, ok bool) {
+	i := 1
+	if i < len(input) && input[i] == '/' {
+		t.closing = true
+		i++
+	}
+	start := i
+	for i < len(input) && (isLetter(input[i]) || (i > start && isDigit(input[i]))) {
+		i++
+	}
+	if i == start {
+		return t, input, false
+	}
+	t.name = strings.ToLower(input[start:i])
+
+	for {
+		for i < len(input) && (isSpace(input[i]) || input[i] == '/') {
+			t.selfClosing = input[i] == '/'
+			i++
+		}
+		if i >= len(input) {
+			return t, input, false
+		}
+		if input[i] == '>' {
+			return t, input[i+1:], true
+		}
+		t.selfClosing = false
+
+		start := i
+		for i < len(input) && !isSpace(input[i]) && input[i] != '=' && input[i] != '>' && input[i] != '/' {
+			i++
+		}
+		a := attribute{name: strings.ToLower(input[start:i])}
+
+		for i < len(input) && isSpace(input[i]) {
+			i++
+		}
+		if i < len(input) && input[i] == '=' {
+			i++
+			for i < len(input) && isSpace(input[i]) {
+				i++
+			}
+			if i < len(input) && (input[i] == '"' || input[i] == '\'') {
+				quote := input[i]
+				end := strings.IndexByte(input[i+1:], quote)
+				if end < 0 {
+					return t, input, false
+				}
+				a.value = input[i+1 : i+1+end]
+				i += end + 2
+			} else {
+				start := i
+				for i < len(input) && !isSpace(input[i]) && input[i] != '>' {
+					i++
+				}
+				a.value = input[start:i]
+			}
+		}
+		t.attributes = append(t.attributes, a)
+	}
+}
+
+func isLetter(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f'
+}
@@ -0,0 +1,92 @@
+package sanitize
+
+import "testing"
+
+const rel = ` rel="nofollow noopener noreferrer"`
+
+func TestSanitizeMarkdown(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		// Allowed markup
+		{"allowed elements", `<p><strong>2</strong> tablets</p>`, `<p><strong>2</strong> tablets</p>`},
+		{"safe link", `<a href="https://dailymed.nlm.nih.gov" title="Label">label</a>`, `<a href="https://dailymed.nlm.nih.gov" title="Label"` + rel + `>label</a>`},
+		{"relative link", `<a href="/chat">chat</a>`, `<a href="/chat"` + rel + `>chat</a>`},
+		{"disallowed attribute", `<p onclick="alert(1)" class="x">hi</p>`, `<p>hi</p>`},
+		{"disallowed element keeps its text", `<div><span>text</span></div>`, `text`},
+		{"comment", `a<!-- <script>alert(1)</script> -->b`, `ab`},
+
+		// javascript: URLs hidden from a plain comparison
+		{"javascript scheme", `<a href="javascript:alert(1)">x</a>`, `<a` + rel + `>x</a>`},
+		{"mixed case scheme", `<a href="JaVaScRiPt:alert(1)">x</a>`, `<a` + rel + `>x</a>`},
+		{"decimal entity", `<a href="&#106;avascript:alert(1)">x</a>`, `<a` + rel + `>x</a>`},
+		{"hex entities", `<a href="&#x6A;&#x61;&#x76;&#x61;script:alert(1)">x</a>`, `<a` + rel + `>x</a>`},
+		{"named colon entity", `<a href="javascript&colon;alert(1)">x</a>`, `<a` + rel + `>x</a>`},
+		{"tab entity in the scheme", `<a href="jav&#x09;ascript:alert(1)">x</a>`, `<a` + rel + `>x</a>`},
+		{"control character", "<a href=\"\x01javascript:alert(1)\">x</a>", `<a` + rel + `>x</a>`},
+		{"leading whitespace", "<a href=\" \n javascript:alert(1)\">x</a>", `<a` + rel + `>x</a>`},
+		{"unquoted value", `<a href=javascript:alert(1)>x</a>`, `<a` + rel + `>x</a>`},
+		{"data scheme", `<a href="data:text/html;base64,PHNjcmlwdD4=">x</a>`, `<a` + rel + `>x</a>`},
+
+		// Duplicate attributes: browsers keep the first one
+		{"duplicate href, safe first", `<a href="https://ok.example" href="javascript:alert(1)">x</a>`, `<a href="https://ok.example"` + rel + `>x</a>`},
+		{"duplicate href, unsafe first", `<a href="javascript:alert(1)" href="https://ok.example">x</a>`, `<a` + rel + `>x</a>`},
+		{"duplicate href, different case", `<a HREF="javascript:alert(1)" href="https://ok.example">x</a>`, `<a` + rel + `>x</a>`},
+
+		// Tags that never end
+		{"unterminated double quote", `<a href="https://x.example>text`, `&lt;a href=&#34;https://x.example&gt;text`},
+		{"unterminated single quote", `<p title='oops>hi</p>`, `&lt;p title=&#39;oops&gt;hi</p>`},
+		{"unterminated tag", `text <a href="https://x.example"`, `text &lt;a href=&#34;https://x.example&#34;`},
+		{"script without closing tag", `before<script>alert(1)`, `before`},
+		{"script with attributes", `<script src="https://evil.example/x.js"></script>after`, `after`},
+		{"uppercase script", `<SCRIPT>alert(1)</SCRIPT>after`, `after`},
+		{"script closing tag in another element", `<script><p>x</p>alert(1)</script>after`, `after`},
+
+		// svg and math are removed with their content
+		{"svg with onload", `<svg onload=alert(1)><circle r="1"/></svg>after`, `after`},
+		{"svg with script", `<svg><script>alert(1)</script></svg>after`, `after`},
+		{"svg with a link", `<svg><a href="javascript:alert(1)"><text>x</text></a></svg>after`, `after`},
+		{"self-closing svg", `<svg/>after`, `after`},
+		{"math with xlink", `<math><mi xlink:href="javascript:alert(1)">x</mi></math>after`, `after`},
+		{"math with a style", `<math><style><img src=x onerror=alert(1)></style></math>after`, `after`},
+
+		// Text is escaped once, whatever it already contains
+		{"plain text", `1 < 2 & 3 > 2`, `1 &lt; 2 &amp; 3 &gt; 2`},
+		{"encoded text", `&lt;script&gt;alert(1)&lt;/script&gt;`, `&lt;script&gt;alert(1)&lt;/script&gt;`},
+		{"double-encoded text", `&amp;lt;script&amp;gt;alert(1)&amp;lt;/script&amp;gt;`, `&amp;lt;script&amp;gt;alert(1)&amp;lt;/script&amp;gt;`},
+		{"encoded ampersand in a link", `<a href="https://x.example/?a=1&amp;b=2">x</a>`, `<a href="https://x.example/?a=1&amp;b=2"` + rel + `>x</a>`},
+		{"quote in an attribute", `<a title='say "hi"' href="/">x</a>`, `<a title="say &#34;hi&#34;" href="/"` + rel + `>x</a>`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Markdown.Sanitize(tt.input); got != tt.want {
+				t.Errorf("Sanitize(%q)\n got %q\nwant %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSafeURL(t *testing.T) {
+	tests := []struct {
+		url  string
+		want bool
+	}{
+		{"https://example.com", true},
+		{"HTTP://example.com", true},
+		{"mailto:someone@example.com", true},
+		{"/relative/path", true},
+		{"#anchor", true},
+		{"javascript:alert(1)", false},
+		{"java\tscript:alert(1)", false},
+		{"\x00javascript:alert(1)", false},
+		{"vbscript:msgbox(1)", false},
+		{"data:text/html,<script>alert(1)</script>", false},
+	}
+	for _, tt := range tests {
+		if got := safeURL(tt.url); got != tt.want {
+			t.Errorf("safeURL(%q) = %v, want %v", tt.url, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,205 @@
+package schema
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Migrator applies the embedded migrations to a MySQL database and records
+// them in the schema_migrations table. MySQL commits DDL statements
+// implicitly, so a migration that fails midway is not recorded and must be
+// fixed by hand before running it again.
+type Migrator struct {
+	db     *sql.DB
+	params Params
+}
+
+// Status is a migration with the time it was applied, nil when pending
+type Status struct {
+	Migration
+	AppliedAt *time.Time
+}
+
+func NewMigrator(db *sql.DB, params Params) *Migrator {
+	return &Migrator{db: db, params: params}
+}
+
+// CreateDatabase creates the tablespace, when one is given, and the database
+// if they do not exist yet. db must be connected without a default database.
+func CreateDatabase(ctx context.Context, db *sql.DB, database string, tablespace string) error {
+	if tablespace != "" {
+		var count int
+		err := db.QueryRowContext(ctx, "SELECT COUNT(*) FROM information_schema.INNODB_TABLESPACES WHERE NAME = ?", tablespace).Scan(&count)
+		if err != nil {
+			return fmt.Errorf("❌ Error reading the tablespaces: %w", err)
+		}
+		if count == 0 {
+			// The data file is created in the data directory of the server
+			statement := fmt.Sprintf("CREATE TABLESPACE %s ADD DATAFILE '%s.ibd' ENGINE=INNODB", QuoteIdentifier(tablespace), tablespace)
+			if _, err := db.ExecContext(ctx, statement); err != nil {
+				return fmt.Errorf("❌ Error creating tablespace %s: %w", tablespace, err)
+			}
+		}
+	}
+
+	if _, err := db.ExecContext(ctx, "CREATE DATABASE IF NOT EXISTS "+QuoteIdentifier(database)); err != nil {
+		return fmt.Errorf("❌ Error creating database %s: %w", database, err)
+	}
+	return nil
+}
+
+func (m *Migrator) init(ctx context.Context) error {
+	_, err := m.db.ExecContext(ctx, `
+	CREATE TABLE IF NOT EXISTS schema_migrations (
+		version INT PRIMARY KEY,
+		name VARCHAR(255),
+		applied_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	)`+m.params.TablespaceClause())
+	if err != nil {
+		return fmt.Errorf("❌ Error creating the schema_migrations table: %w", err)
+	}
+	return nil
+}
+
+// Status lists every migration, applied or not, ordered by version
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	migrations, err := Migrations()
+	if err != nil {
+		return nil, err
+	}
+	if err := m.init(ctx); err != nil {
+		return nil, err
+	}
+
+	rows, err := m.db.QueryContext(ctx, "SELECT version, applied_at FROM schema_migrations")
+	if err != nil {
+		return nil, fmt.Errorf("❌ Error reading the schema_migrations table: %w", err)
+	}
+	defer rows.Close()
+
+	applied := make(map[int]time.Time)
+	for rows.Next() {
+		var version int
+		var appliedAt time.Time
+		if err := rows.Scan(&version, &appliedAt); err != nil {
+			return nil, fmt.Errorf("❌ Error scanning row: %w", err)
+		}
+		applied[version] = appliedAt
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("❌ Error reading the schema_migrations table: %w", err)
+	}
+
+	status := make([]Status, len(migrations))
+	for i, migration := range migrations {
+		status[i].Migration = migration
+		if appliedAt, ok := applied[migration.Version]; ok {
+			status[i].AppliedAt = &appliedAt
+		}
+	}
+	return status, nil
+}
+
+// Up applies the pending migrations in order, at most steps of them when
+// steps is positive, and returns the migrations applied
+func (m *Migrator) Up(ctx context.Context, steps int) ([]Migration, error) {
+	status, err := m.Status(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var done []Migration
+	for _, s := range status {
+		if s.AppliedAt != nil {
+			continue
+		}
+		if steps > 0 && len(done) == steps {
+			break
+		}
+		if m.params.Dimension <= 0 {
+			return done, fmt.Errorf("❌ Migration %s needs the dimension of the embedding model", s.Migration)
+		}
+		if err := m.run(ctx, s.Migration, s.up); err != nil {
+			return done, err
+		}
+		if _, err := m.db.ExecContext(ctx, "INSERT INTO schema_migrations (version, name) VALUES (?, ?)", s.Version, s.Name); err != nil {
+			return done, fmt.Errorf("❌ Error recording migration %s: %w", s.Migration, err)
+		}
+		done = append(done, s.Migration)
+	}
+	return done, nil
+}
+
+// Down reverts the applied migrations from the latest, at most steps of them
+// when steps is positive, and returns the migrations reverted
+func (m *Migrator) Down(ctx context.Context, steps int) ([]Migration, error) {
+	status, err := m.Status(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var done []Migration
+	for i := len(status) - 1; i >= 0; i-- {
+		s := status[i]
+		if s.AppliedAt == nil {
+			continue
+		}
+		if steps > 0 && len(done) == steps {
+			break
+		}
+		if err := m.run(ctx, s.Migration, s.down); err != nil {
+			return done, err
+		}
+		if _, err := m.db.ExecContext(ctx, "DELETE FROM schema_migrations WHERE version = ?", s.Version); err != nil {
+			return done, fmt.Errorf("❌ Error recording migration %s: %w", s.Migration, err)
+		}
+		done = append(done, s.Migration)
+	}
+	return done, nil
+}
+
+func (m *Migrator) run(ctx context.Context, migration Migration, text string) error {
+	params := m.params
+	columns, err := m.columns(ctx)
+	if err != nil {
+		return err
+	}
+	params.Columns = columns
+
+	statements, err := migration.statements(text, params)
+	if err != nil {
+		return err
+	}
+	for _, statement := range statements {
+		if _, err := m.db.ExecContext(ctx, statement); err != nil {
+			return fmt.Errorf("❌ Error running migration %s: %w", migration, err)
+		}
+	}
+	return nil
+}
+
+// columns returns the type of each column of the database, such as
+// vector(1024), keyed on table.column
+func (m *Migrator) columns(ctx context.Context) (map[string]string, error) {
+	rows, err := m.db.QueryContext(ctx, "SELECT TABLE_NAME, COLUMN_NAME, COLUMN_TYPE FROM information_schema.COLUMNS WHERE TABLE_SCHEMA = DATABASE()")
+	if err != nil {
+		return nil, fmt.Errorf("❌ Error reading the columns: %w", err)
+	}
+	defer rows.Close()
+
+	columns := make(map[string]string)
+	for rows.Next() {
+		var table, column, columnType string
+		if err := rows.Scan(&table, &column, &columnType); err != nil {
+			return nil, fmt.Errorf("❌ Error scanning row: %w", err)
+		}
+		columns[strings.ToLower(table+"."+column)] = strings.ToLower(columnType)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("❌ Error reading the columns: %w", err)
+	}
+	return columns, nil
+}
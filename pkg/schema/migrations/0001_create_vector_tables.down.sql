@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS medicationv;
+DROP TABLE IF EXISTS pathologies;
+DROP TABLE IF EXISTS store_metadata;
@@ -0,0 +1,37 @@
+-- Pathologies and medications with their embeddings. The width of the
+-- VECTOR columns is the output dimension of the embedding model.
+
+CREATE TABLE IF NOT EXISTS pathologies (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    name VARCHAR(255) UNIQUE,
+    content_hash CHAR(64),
+    embedding VECTOR({{.Dimension}})
+){{.TablespaceClause}};
+
+CREATE TABLE IF NOT EXISTS medicationv (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    pathologie_id INT,
+    set_id VARCHAR(64),
+    version VARCHAR(16),
+    content_hash CHAR(64),
+    drug_name VARCHAR(255),
+    inactive_ingredient TEXT,
+    purpose TEXT,
+    keep_out_of_reach_of_children TEXT,
+    warnings TEXT,
+    spl_product_data_elements TEXT,
+    dosage_and_administration TEXT,
+    pregnancy_or_breast_feeding TEXT,
+    package_label_principal_display_panel TEXT,
+    indications_and_usage TEXT,
+    embedding VECTOR({{.Dimension}}),
+    UNIQUE KEY uk_pathologie_set (pathologie_id, set_id),
+    CONSTRAINT fk_pathologie FOREIGN KEY (pathologie_id) REFERENCES pathologies(id)
+){{.TablespaceClause}};
+
+-- Embedding model and dimension of the stored vectors
+
+CREATE TABLE IF NOT EXISTS store_metadata (
+    name VARCHAR(64) PRIMARY KEY,
+    value VARCHAR(255)
+){{.TablespaceClause}};
@@ -0,0 +1,19 @@
+-- Chat conversations and their messages
+
+CREATE TABLE IF NOT EXISTS conversations (
+    id CHAR(32) PRIMARY KEY,
+    pathology VARCHAR(255),
+    context MEDIUMTEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    ended_at TIMESTAMP NULL
+){{.TablespaceClause}};
+
+CREATE TABLE IF NOT EXISTS messages (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    conversation_id CHAR(32),
+    role VARCHAR(16),
+    content MEDIUMTEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_conversation FOREIGN KEY (conversation_id) REFERENCES conversations(id)
+){{.TablespaceClause}};
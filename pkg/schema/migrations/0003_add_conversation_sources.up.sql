@@ -0,0 +1,3 @@
+-- Medications the answers of a conversation can cite
+
+ALTER TABLE conversations ADD COLUMN sources JSON AFTER context;
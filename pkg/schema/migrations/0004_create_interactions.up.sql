@@ -0,0 +1,12 @@
+-- Known interactions between active ingredients, stored lowercased with
+-- ingredient_a < ingredient_b
+
+CREATE TABLE IF NOT EXISTS interactions (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    ingredient_a VARCHAR(255) NOT NULL,
+    ingredient_b VARCHAR(255) NOT NULL,
+    severity VARCHAR(32) NOT NULL,
+    description TEXT,
+    source VARCHAR(255),
+    UNIQUE KEY uk_ingredients (ingredient_a, ingredient_b)
+){{.TablespaceClause}};
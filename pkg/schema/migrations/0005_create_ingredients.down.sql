@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS medication_ingredients;
+DROP TABLE IF EXISTS ingredients;
@@ -0,0 +1,17 @@
+-- Canonical active ingredients of the medications. medication_ingredients
+-- has no foreign key to medicationv: the re-embedding swaps medicationv
+-- with RENAME TABLE, and the store removes the links of a medication.
+
+CREATE TABLE IF NOT EXISTS ingredients (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    UNIQUE KEY uk_ingredient_name (name)
+){{.TablespaceClause}};
+
+CREATE TABLE IF NOT EXISTS medication_ingredients (
+    medication_id INT NOT NULL,
+    ingredient_id INT NOT NULL,
+    PRIMARY KEY (medication_id, ingredient_id),
+    KEY idx_ingredient (ingredient_id),
+    CONSTRAINT fk_ingredient FOREIGN KEY (ingredient_id) REFERENCES ingredients(id)
+){{.TablespaceClause}};
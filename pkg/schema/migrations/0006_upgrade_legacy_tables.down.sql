@@ -0,0 +1 @@
+-- The upgraded columns are part of the tables of 0001, which drops them
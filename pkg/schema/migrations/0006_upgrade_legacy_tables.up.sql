@@ -0,0 +1,28 @@
+-- Upgrade the vector tables created by hand before the schema had
+-- migrations: 0001 keeps existing tables as they are. Each change is only
+-- made when the column is missing or has another width. Medications
+-- without set_id are replaced by the next import.
+
+{{if not (index .Columns "pathologies.content_hash")}}
+ALTER TABLE pathologies ADD COLUMN content_hash CHAR(64) AFTER name;
+{{end}}
+{{if not (index .Columns "medicationv.set_id")}}
+ALTER TABLE medicationv
+    ADD COLUMN set_id VARCHAR(64) AFTER pathologie_id,
+    ADD UNIQUE KEY uk_pathologie_set (pathologie_id, set_id);
+{{end}}
+{{if not (index .Columns "medicationv.version")}}
+ALTER TABLE medicationv ADD COLUMN version VARCHAR(16) AFTER set_id;
+{{end}}
+{{if not (index .Columns "medicationv.content_hash")}}
+ALTER TABLE medicationv ADD COLUMN content_hash CHAR(64) AFTER version;
+{{end}}
+
+-- The stored vectors must not be longer than the new width
+
+{{if ne (index .Columns "pathologies.embedding") (printf "vector(%d)" .Dimension)}}
+ALTER TABLE pathologies MODIFY embedding VECTOR({{.Dimension}});
+{{end}}
+{{if ne (index .Columns "medicationv.embedding") (printf "vector(%d)" .Dimension)}}
+ALTER TABLE medicationv MODIFY embedding VECTOR({{.Dimension}});
+{{end}}
@@ -0,0 +1,131 @@
+package schema
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+// The migrations are pairs of <version>_<name>.up.sql and
+// <version>_<name>.down.sql files, rendered as text/template with Params
+//
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+var migrationName = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+// Params are the values substituted in the migrations
+type Params struct {
+	// Tablespace receives the tables when it is not empty
+	Tablespace string
+	// Dimension is the width of the VECTOR columns, the output dimension
+	// of the embedding model
+	Dimension int
+	// Columns are the types of the existing columns, keyed on
+	// table.column, read before each migration so it can upgrade the
+	// tables created by hand
+	Columns map[string]string
+}
+
+// TablespaceClause is appended to the CREATE TABLE statements
+func (p Params) TablespaceClause() string {
+	if p.Tablespace == "" {
+		return ""
+	}
+	return " TABLESPACE " + QuoteIdentifier(p.Tablespace)
+}
+
+// Migration is a versioned change of the schema
+type Migration struct {
+	Version int
+	Name    string
+	up      string
+	down    string
+}
+
+// Migrations returns the embedded migrations ordered by version
+func Migrations() ([]Migration, error) {
+	entries, err := fs.ReadDir(migrationFiles, "migrations")
+	if err != nil {
+		return nil, fmt.Errorf("❌ Error reading the migrations: %w", err)
+	}
+
+	byVersion := make(map[int]*Migration)
+	for _, entry := range entries {
+		parts := migrationName.FindStringSubmatch(entry.Name())
+		if parts == nil {
+			return nil, fmt.Errorf("❌ Invalid migration file name: %s", entry.Name())
+		}
+		version, _ := strconv.Atoi(parts[1])
+		content, err := migrationFiles.ReadFile("migrations/" + entry.Name())
+		if err != nil {
+			return nil, fmt.Errorf("❌ Error reading migration %s: %w", entry.Name(), err)
+		}
+
+		migration, ok := byVersion[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: parts[2]}
+			byVersion[version] = migration
+		}
+		if migration.Name != parts[2] {
+			return nil, fmt.Errorf("❌ Migration %d has two names: %s and %s", version, migration.Name, parts[2])
+		}
+		if parts[3] == "up" {
+			migration.up = string(content)
+		} else {
+			migration.down = string(content)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if migration.up == "" || migration.down == "" {
+			return nil, fmt.Errorf("❌ Migration %04d_%s needs an up and a down file", migration.Version, migration.Name)
+		}
+		migrations = append(migrations, *migration)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+	return migrations, nil
+}
+
+func (m Migration) String() string {
+	return fmt.Sprintf("%04d_%s", m.Version, m.Name)
+}
+
+// statements renders a migration file and splits it into statements
+func (m Migration) statements(text string, params Params) ([]string, error) {
+	tpl, err := template.New(m.String()).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("❌ Error parsing migration %s: %w", m, err)
+	}
+	var rendered strings.Builder
+	if err := tpl.Execute(&rendered, params); err != nil {
+		return nil, fmt.Errorf("❌ Error rendering migration %s: %w", m, err)
+	}
+
+	var statements []string
+	for _, statement := range strings.Split(rendered.String(), ";") {
+		var lines []string
+		for _, line := range strings.Split(statement, "\n") {
+			if !strings.HasPrefix(strings.TrimSpace(line), "--") {
+				lines = append(lines, line)
+			}
+		}
+		if statement := strings.TrimSpace(strings.Join(lines, "\n")); statement != "" {
+			statements = append(statements, statement)
+		}
+	}
+	return statements, nil
+}
+
+// QuoteIdentifier quotes a database, table or tablespace name for MySQL
+func QuoteIdentifier(name string) string {
+	return "`" + strings.ReplaceAll(name, "`", "``") + "`"
+}
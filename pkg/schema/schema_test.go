@@ -0,0 +1,70 @@
+package schema
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestUpgradeLegacyTables(t *testing.T) {
+	migrations, err := Migrations()
+	if err != nil {
+		t.Fatalf("Migrations: %v", err)
+	}
+	var upgrade Migration
+	for _, m := range migrations {
+		if m.Name == "upgrade_legacy_tables" {
+			upgrade = m
+		}
+	}
+	if upgrade.up == "" {
+		t.Fatal("no upgrade_legacy_tables migration")
+	}
+
+	current := map[string]string{
+		"pathologies.name":         "varchar(255)",
+		"pathologies.content_hash": "char(64)",
+		"pathologies.embedding":    "vector(1024)",
+		"medicationv.set_id":       "varchar(64)",
+		"medicationv.version":      "varchar(16)",
+		"medicationv.content_hash": "char(64)",
+		"medicationv.embedding":    "vector(1024)",
+	}
+	// Tables of the first version, before the import identity
+	legacy := map[string]string{
+		"pathologies.name":      "varchar(255)",
+		"pathologies.embedding": "vector(10000)",
+		"medicationv.embedding": "vector(10000)",
+	}
+
+	tests := []struct {
+		name    string
+		columns map[string]string
+		want    []string
+	}{
+		{"current tables", current, nil},
+		{"legacy tables", legacy, []string{
+			"ALTER TABLE pathologies ADD COLUMN content_hash CHAR(64) AFTER name",
+			"ALTER TABLE medicationv ADD COLUMN set_id VARCHAR(64) AFTER pathologie_id, ADD UNIQUE KEY uk_pathologie_set (pathologie_id, set_id)",
+			"ALTER TABLE medicationv ADD COLUMN version VARCHAR(16) AFTER set_id",
+			"ALTER TABLE medicationv ADD COLUMN content_hash CHAR(64) AFTER version",
+			"ALTER TABLE pathologies MODIFY embedding VECTOR(1024)",
+			"ALTER TABLE medicationv MODIFY embedding VECTOR(1024)",
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			statements, err := upgrade.statements(upgrade.up, Params{Dimension: 1024, Columns: tt.columns})
+			if err != nil {
+				t.Fatalf("statements: %v", err)
+			}
+			var got []string
+			for _, statement := range statements {
+				got = append(got, strings.Join(strings.Fields(statement), " "))
+			}
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("statements =\n%q\nwant\n%q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,431 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	configPkg "github.com/colussim/go-mysql-ai/pkg/config"
+	"github.com/colussim/go-mysql-ai/pkg/session"
+	"github.com/colussim/go-mysql-ai/pkg/store"
+	"github.com/colussim/go-mysql-ai/pkg/symptoms"
+)
+
+const apiPrefix = "/api/v1"
+
+// Largest request body accepted by the API
+const maxRequestBody = 1 << 20
+
+// ChatRequest is a question sent to POST /api/v1/chat. Without a
+// conversation ID a new conversation is started.
+type ChatRequest struct {
+	Message        string          `json:"message" description:"Question of the user"`
+	ConversationID string          `json:"conversation_id,omitempty" description:"Conversation to continue, as returned by a previous answer"`
+	Profile        *PatientProfile `json:"profile,omitempty" description:"Patient the question is about: unsuitable medications are excluded, the others get cautions"`
+}
+
+// ChatResponse is the answer to a ChatRequest. Answered is false when the
+// question matched no pathology, Markdown then lists the supported ones,
+// when it has red-flag symptoms, Markdown then gives the Triage guidance,
+// or when its symptoms fit several pathologies, Markdown then asks the
+// Clarification.
+type ChatResponse struct {
+	ConversationID string               `json:"conversation_id"`
+	Answered       bool                 `json:"answered"`
+	Markdown       string               `json:"markdown" description:"Answer as generated by the model, with the cautions and interactions above it and the exclusions and matched symptoms below, as on the chat page"`
+	HTML           string               `json:"html" description:"Answer rendered to sanitized HTML"`
+	Pathology      *PathologyMatch      `json:"pathology" description:"Pathology recognized in the question, null for follow-up questions"`
+	Sources        []Source             `json:"sources" description:"Medication labels the answer can cite, also for follow-up questions"`
+	Interactions   []InteractionWarning `json:"interactions" description:"Drug interactions among the sources and the medications mentioned by the user"`
+	Exclusions     []Exclusion          `json:"exclusions" description:"Retrieved medications left out for the patient profile, with the reason"`
+	Triage         *Triage              `json:"triage" description:"Urgent-care guidance given instead of an answer when the question has red-flag symptoms, null otherwise. No medication is retrieved and the model is not called."`
+	Differential   []symptoms.Candidate `json:"differential" description:"Pathologies whose symptoms the question describes, the most likely first, with the symptoms matched"`
+	Clarification  string               `json:"clarification,omitempty" description:"Question asked instead of an answer when several pathologies fit the symptoms as well; answer it in the same conversation"`
+}
+
+// PathologyMatch is the pathology a question was matched with
+type PathologyMatch struct {
+	ID              int     `json:"id"`
+	Name            string  `json:"name"`
+	SimilarityScore float64 `json:"similarity_score"`
+}
+
+// Source is a medication label retrieved to ground an answer
+type Source struct {
+	Marker          int      `json:"marker" description:"Number cited in the answer, e.g. [1]"`
+	ID              int      `json:"id" description:"ID of the medication"`
+	DrugName        string   `json:"drug_name"`
+	Pathology       string   `json:"pathology"`
+	SetID           string   `json:"set_id,omitempty" description:"OpenFDA label set ID"`
+	Sections        []string `json:"sections" description:"Label sections given to the model"`
+	Ingredients     []string `json:"ingredients" description:"Canonical active ingredients"`
+	OtherBrands     []string `json:"other_brands" description:"Brands of the same active ingredients folded into this source"`
+	Cautions        []string `json:"cautions" description:"Cautions for the patient profile of the question"`
+	SimilarityScore float64  `json:"similarity_score"`
+	Cited           bool     `json:"cited" description:"Whether the answer cites this source"`
+}
+
+// PathologyInfo describes a supported pathology
+type PathologyInfo struct {
+	ID          int      `json:"id"`
+	Name        string   `json:"name"`
+	Description string   `json:"description,omitempty"`
+	Symptoms    []string `json:"symptoms"`
+	Treatments  []string `json:"treatments"`
+}
+
+type PathologyList struct {
+	Pathologies []PathologyInfo `json:"pathologies"`
+}
+
+type InteractionList struct {
+	Interactions []store.Interaction `json:"interactions"`
+}
+
+type MedicationList struct {
+	Pathology   string             `json:"pathology"`
+	Medications []store.Medication `json:"medications"`
+}
+
+// ErrorResponse is the body of every API error
+type ErrorResponse struct {
+	Error APIError `json:"error"`
+}
+
+type APIError struct {
+	Status  int    `json:"status" description:"HTTP status code"`
+	Code    string `json:"code" description:"Machine readable error code"`
+	Message string `json:"message"`
+}
+
+// apiParameter is a parameter of an API route, in its path unless query
+// is set. Query parameters are optional and may be repeated.
+type apiParameter struct {
+	name        string
+	kind        string
+	description string
+	query       bool
+}
+
+// apiRoute is an endpoint of the API. The routes are served and documented
+// in the OpenAPI document from this table.
+type apiRoute struct {
+	method      string
+	path        string
+	operationID string
+	summary     string
+	parameters  []apiParameter
+	request     any
+	response    any
+	errors      []int
+	handler     http.HandlerFunc
+}
+
+func apiRoutes() []apiRoute {
+	return []apiRoute{
+		{
+			method:      http.MethodPost,
+			path:        apiPrefix + "/chat",
+			operationID: "chat",
+			summary:     "Ask a question, optionally in an existing conversation",
+			request:     ChatRequest{},
+			response:    ChatResponse{},
+			errors:      []int{http.StatusBadRequest, http.StatusNotFound, http.StatusInternalServerError},
+			handler:     apiChatHandler,
+		},
+		{
+			method:      http.MethodGet,
+			path:        apiPrefix + "/pathologies",
+			operationID: "listPathologies",
+			summary:     "List the supported pathologies",
+			response:    PathologyList{},
+			errors:      []int{http.StatusInternalServerError},
+			handler:     apiPathologiesHandler,
+		},
+		{
+			method:      http.MethodGet,
+			path:        apiPrefix + "/pathologies/{name}/medications",
+			operationID: "listPathologyMedications",
+			summary:     "List the medications stored for a pathology",
+			parameters:  []apiParameter{{name: "name", kind: "string", description: "Name of the pathology"}},
+			response:    MedicationList{},
+			errors:      []int{http.StatusNotFound, http.StatusInternalServerError},
+			handler:     apiPathologyMedicationsHandler,
+		},
+		{
+			method:      http.MethodGet,
+			path:        apiPrefix + "/medications/{id}",
+			operationID: "getMedication",
+			summary:     "Get a medication",
+			parameters:  []apiParameter{{name: "id", kind: "integer", description: "ID of the medication"}},
+			response:    store.Medication{},
+			errors:      []int{http.StatusBadRequest, http.StatusNotFound, http.StatusInternalServerError},
+			handler:     apiMedicationHandler,
+		},
+		{
+			method:      http.MethodGet,
+			path:        apiPrefix + "/interactions",
+			operationID: "listInteractions",
+			summary:     "List the known drug interactions, or the ones between the given ingredients",
+			parameters: []apiParameter{{
+				name:        "ingredient",
+				kind:        "string",
+				description: "Active ingredient. With one, the interactions of this ingredient; with several, the interactions between them",
+				query:       true,
+			}},
+			response: InteractionList{},
+			errors:   []int{http.StatusInternalServerError},
+			handler:  apiInteractionsHandler,
+		},
+		{
+			method:      http.MethodGet,
+			path:        apiPrefix + "/openapi.json",
+			operationID: "getOpenAPI",
+			summary:     "OpenAPI description of this API",
+			handler:     openAPIHandler,
+		},
+	}
+}
+
+// apiHandler serves the routes of apiRoutes. Unknown paths and methods get
+// an ErrorResponse too.
+func apiHandler() http.Handler {
+	mux := http.NewServeMux()
+	for _, route := range apiRoutes() {
+		mux.HandleFunc(route.method+" "+route.path, route.handler)
+	}
+
+	mux.HandleFunc(apiPrefix+"/", func(w http.ResponseWriter, r *http.Request) {
+		for _, route := range apiRoutes() {
+			if route.method == r.Method {
+				continue
+			}
+			probe := r.Clone(r.Context())
+			probe.Method = route.method
+			if _, pattern := mux.Handler(probe); pattern == route.method+" "+route.path {
+				sendAPIError(w, http.StatusMethodNotAllowed, "method_not_allowed", fmt.Sprintf("Use %s on %s", route.method, r.URL.Path))
+				return
+			}
+		}
+		sendAPIError(w, http.StatusNotFound, "not_found", "No API endpoint at "+r.URL.Path)
+	})
+	return mux
+}
+
+func apiChatHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var request ChatRequest
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestBody))
+	if err := decoder.Decode(&request); err != nil && !errors.Is(err, io.EOF) {
+		sendAPIError(w, http.StatusBadRequest, "invalid_request", "Invalid JSON body: "+err.Error())
+		return
+	}
+	if request.Message == "" {
+		sendAPIError(w, http.StatusBadRequest, "invalid_request", "message is required")
+		return
+	}
+	if request.Profile != nil {
+		if err := request.Profile.Validate(); err != nil {
+			sendAPIError(w, http.StatusBadRequest, "invalid_request", "Invalid profile: "+err.Error())
+			return
+		}
+	}
+
+	var conversation *session.Conversation
+	var err error
+	if request.ConversationID == "" {
+		conversation, err = sessions.Create(ctx)
+	} else {
+		conversation, err = sessions.Get(ctx, request.ConversationID)
+		if err == nil && conversation == nil {
+			sendAPIError(w, http.StatusNotFound, "conversation_not_found", "Unknown or ended conversation: "+request.ConversationID)
+			return
+		}
+	}
+	if err != nil {
+		sendAPIError(w, http.StatusInternalServerError, "internal_error", "Error loading conversation: "+err.Error())
+		return
+	}
+
+	response := ChatResponse{ConversationID: conversation.ID, Sources: []Source{}, Interactions: []InteractionWarning{}, Exclusions: []Exclusion{}, Differential: []symptoms.Candidate{}}
+
+	// Red-flag symptoms get urgent-care guidance, without medications
+	queryEmbedding, urgent, err := triageAndEmbed(ctx, conversation, request.Message, request.Profile)
+	if err != nil {
+		sendAPIError(w, http.StatusInternalServerError, "internal_error", "Error generating embedding: "+err.Error())
+		return
+	}
+	if urgent != nil {
+		response.Triage = urgent
+		response.Markdown = triageMarkdown(urgent)
+		response.HTML = string(markdownToHTML2(response.Markdown))
+		sendAPIJSON(w, http.StatusOK, response)
+		return
+	}
+	inferred, err := inferPathology(ctx, conversation, request.Message, queryEmbedding)
+	if err != nil {
+		sendAPIError(w, http.StatusInternalServerError, "internal_error", "Error matching pathology: "+err.Error())
+		return
+	}
+	response.Differential = nonNil(inferred.differential)
+	if inferred.clarification != "" {
+		if err := askClarification(ctx, conversation, request.Message, inferred); err != nil {
+			sendAPIError(w, http.StatusInternalServerError, "internal_error", "Error saving conversation: "+err.Error())
+			return
+		}
+		response.Clarification = inferred.clarification
+		response.Markdown = inferred.clarification
+		response.HTML = string(markdownToHTML2(response.Markdown))
+		sendAPIJSON(w, http.StatusOK, response)
+		return
+	}
+	match := inferred.match
+
+	if match == nil && conversation.Context == "" {
+		response.Markdown = unsupportedPathologyMessage()
+		response.HTML = string(markdownToHTML2(response.Markdown))
+		sendAPIJSON(w, http.StatusOK, response)
+		return
+	}
+
+	result, err := generateResponse(ctx, conversation, match, queryEmbedding, request.Message, request.Profile, nil)
+	if err != nil {
+		sendAPIError(w, http.StatusInternalServerError, "internal_error", "Error generating response: "+err.Error())
+		return
+	}
+
+	answer := result.Text
+	response.Answered = true
+	response.Markdown = answerMarkdown(conversation, result, inferred.differential)
+	response.HTML = string(renderAnswer(response.Markdown, conversation.Sources))
+	if match != nil {
+		response.Pathology = &PathologyMatch{ID: match.ID, Name: match.Name, SimilarityScore: match.SimilarityScore}
+	}
+	response.Sources = answerSources(conversation, answer)
+	response.Interactions = apiWarnings(result.Warnings)
+	response.Exclusions = nonNil(result.Exclusions)
+	sendAPIJSON(w, http.StatusOK, response)
+}
+
+func apiPathologiesHandler(w http.ResponseWriter, r *http.Request) {
+	pathologies, err := vectorStore.ListPathologies(r.Context())
+	if err != nil {
+		sendAPIError(w, http.StatusInternalServerError, "internal_error", err.Error())
+		return
+	}
+
+	list := PathologyList{Pathologies: []PathologyInfo{}}
+	for _, p := range pathologies {
+		details := pathology.Pathologies[p.Name]
+		list.Pathologies = append(list.Pathologies, PathologyInfo{
+			ID:          p.ID,
+			Name:        p.Name,
+			Description: details.Description,
+			Symptoms:    nonNil(details.Symptoms),
+			Treatments:  nonNil(details.Treatments),
+		})
+	}
+	sendAPIJSON(w, http.StatusOK, list)
+}
+
+func apiPathologyMedicationsHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	name := r.PathValue("name")
+
+	pathologies, err := vectorStore.ListPathologies(ctx)
+	if err != nil {
+		sendAPIError(w, http.StatusInternalServerError, "internal_error", err.Error())
+		return
+	}
+	for _, p := range pathologies {
+		if p.Name != name {
+			continue
+		}
+		medications, err := vectorStore.ListMedications(ctx, p.ID)
+		if err != nil {
+			sendAPIError(w, http.StatusInternalServerError, "internal_error", err.Error())
+			return
+		}
+		sendAPIJSON(w, http.StatusOK, MedicationList{Pathology: p.Name, Medications: nonNil(medications)})
+		return
+	}
+	sendAPIError(w, http.StatusNotFound, "pathology_not_found", "Unknown pathology: "+name)
+}
+
+func apiMedicationHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil || id <= 0 {
+		sendAPIError(w, http.StatusBadRequest, "invalid_request", "Invalid medication ID: "+r.PathValue("id"))
+		return
+	}
+
+	medication, err := vectorStore.GetMedication(r.Context(), id)
+	if err != nil {
+		sendAPIError(w, http.StatusInternalServerError, "internal_error", err.Error())
+		return
+	}
+	if medication == nil {
+		sendAPIError(w, http.StatusNotFound, "medication_not_found", fmt.Sprintf("Unknown medication: %d", id))
+		return
+	}
+	sendAPIJSON(w, http.StatusOK, medication)
+}
+
+func apiInteractionsHandler(w http.ResponseWriter, r *http.Request) {
+	list := InteractionList{Interactions: []store.Interaction{}}
+	interactionStore, ok := vectorStore.(store.InteractionStore)
+	if !ok {
+		sendAPIJSON(w, http.StatusOK, list)
+		return
+	}
+	table, err := interactionStore.ListInteractions(r.Context())
+	if err != nil {
+		sendAPIError(w, http.StatusInternalServerError, "internal_error", err.Error())
+		return
+	}
+
+	// The stored interactions are keyed on canonical ingredients, so
+	// "Naproxen Sodium" finds those of naproxen
+	ingredients := make(map[string]bool)
+	for _, ingredient := range r.URL.Query()["ingredient"] {
+		ingredients[store.CanonicalIngredient(ingredient)] = true
+	}
+	for _, i := range table {
+		switch {
+		case len(ingredients) == 0:
+		case len(ingredients) == 1 && !ingredients[i.IngredientA] && !ingredients[i.IngredientB]:
+			continue
+		case len(ingredients) > 1 && !(ingredients[i.IngredientA] && ingredients[i.IngredientB]):
+			continue
+		}
+		list.Interactions = append(list.Interactions, i)
+	}
+	sendAPIJSON(w, http.StatusOK, list)
+}
+
+func sendAPIJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		configPkg.Log.Errorf("❌ Error encoding response: %v", err)
+	}
+}
+
+func sendAPIError(w http.ResponseWriter, status int, code string, message string) {
+	if status >= http.StatusInternalServerError {
+		configPkg.Log.Errorf("❌ API error: %s", message)
+	}
+	sendAPIJSON(w, status, ErrorResponse{Error: APIError{Status: status, Code: code, Message: message}})
+}
+
+// nonNil encodes missing lists as [] rather than null
+func nonNil[T any](values []T) []T {
+	if values == nil {
+		return []T{}
+	}
+	return values
+}
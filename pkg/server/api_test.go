@@ -0,0 +1,53 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/colussim/go-mysql-ai/pkg/store"
+)
+
+func TestAPIInteractionsHandler(t *testing.T) {
+	memory := store.NewMemory()
+	err := memory.UpsertInteractions(context.Background(), []*store.Interaction{
+		{IngredientA: "aspirin", IngredientB: "naproxen", Severity: "moderate"},
+		{IngredientA: "naproxen", IngredientB: "warfarin", Severity: "major"},
+		{IngredientA: "acetaminophen", IngredientB: "warfarin", Severity: "minor"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	saved := vectorStore
+	vectorStore = memory
+	t.Cleanup(func() { vectorStore = saved })
+
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{"", []string{"acetaminophen+warfarin", "aspirin+naproxen", "naproxen+warfarin"}},
+		{"?ingredient=naproxen", []string{"aspirin+naproxen", "naproxen+warfarin"}},
+		{"?ingredient=Naproxen%20Sodium%20220%20mg", []string{"aspirin+naproxen", "naproxen+warfarin"}},
+		{"?ingredient=Warfarin%20Sodium&ingredient=naproxen", []string{"naproxen+warfarin"}},
+		{"?ingredient=ibuprofen", []string{}},
+	}
+	for _, tt := range tests {
+		recorder := httptest.NewRecorder()
+		apiInteractionsHandler(recorder, httptest.NewRequest("GET", "/api/v1/interactions"+tt.query, nil))
+
+		var list InteractionList
+		if err := json.NewDecoder(recorder.Body).Decode(&list); err != nil {
+			t.Fatalf("%s: %v", tt.query, err)
+		}
+		got := []string{}
+		for _, i := range list.Interactions {
+			got = append(got, i.IngredientA+"+"+i.IngredientB)
+		}
+		if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+			t.Errorf("interactions%s = %v, want %v", tt.query, got, tt.want)
+		}
+	}
+}
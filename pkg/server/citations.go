@@ -0,0 +1,150 @@
+package server
+
+import (
+	"html/template"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/colussim/go-mysql-ai/pkg/session"
+	"github.com/colussim/go-mysql-ai/pkg/store"
+)
+
+// citationInstruction is appended to the prompt after the numbered medications
+const citationInstruction = "\n\nBase your answer only on the medications listed above. After each statement, cite the medication labels it comes from with their marker, for example [1] or [1, 2]. Never cite a marker that is not listed."
+
+// citationPattern matches the markers of an answer: [1], [2, 3]
+var citationPattern = regexp.MustCompile(`\[(\d+(?:\s*,\s*\d+)*)\]`)
+
+// citeMedications numbers the medications given to the model, from 1, and
+// records the label sections quoted and the active ingredients of each
+func citeMedications(medications []store.Medication) []session.Source {
+	sources := make([]session.Source, 0, len(medications))
+	for i, med := range medications {
+		sources = append(sources, session.Source{
+			Marker:          i + 1,
+			MedicationID:    med.ID,
+			DrugName:        med.DrugName,
+			SetID:           med.SetID,
+			Sections:        labelSections(med),
+			SimilarityScore: med.SimilarityScore,
+			Ingredients:     medicationIngredients(med),
+			OtherBrands:     med.OtherBrands,
+			Cautions:        med.Cautions,
+		})
+	}
+	return sources
+}
+
+// labelSections lists the OpenFDA label sections of a medication that are
+// quoted in the prompt
+func labelSections(med store.Medication) []string {
+	sections := []struct {
+		name string
+		text string
+	}{
+		{"indications_and_usage", med.Indications},
+		{"purpose", med.Purpose},
+		{"dosage_and_administration", med.Dosage},
+		{"warnings", med.Warnings},
+		{"package_label_principal_display_panel", med.PackageLabel},
+	}
+
+	names := []string{}
+	for _, section := range sections {
+		if strings.TrimSpace(section.text) != "" {
+			names = append(names, section.name)
+		}
+	}
+	return names
+}
+
+// citationMarkers returns the markers of a citation, or nil when one of them
+// is not a source
+func citationMarkers(citation string, sources []session.Source) []int {
+	var markers []int
+	for _, part := range strings.Split(citation, ",") {
+		marker, err := strconv.Atoi(strings.TrimSpace(part))
+		if err != nil || marker < 1 || marker > len(sources) {
+			return nil
+		}
+		markers = append(markers, marker)
+	}
+	return markers
+}
+
+// answerSources lists the sources of a conversation, flagging the ones the
+// answer cites
+func answerSources(conversation *session.Conversation, answer string) []Source {
+	cited := make(map[int]bool)
+	for _, match := range citationPattern.FindAllStringSubmatch(answer, -1) {
+		for _, marker := range citationMarkers(match[1], conversation.Sources) {
+			cited[marker] = true
+		}
+	}
+
+	sources := make([]Source, 0, len(conversation.Sources))
+	for _, s := range conversation.Sources {
+		sources = append(sources, Source{
+			Marker:          s.Marker,
+			ID:              s.MedicationID,
+			DrugName:        s.DrugName,
+			Pathology:       conversation.Pathology,
+			SetID:           s.SetID,
+			Sections:        nonNil(s.Sections),
+			Ingredients:     nonNil(s.Ingredients),
+			OtherBrands:     nonNil(s.OtherBrands),
+			Cautions:        nonNil(s.Cautions),
+			SimilarityScore: s.SimilarityScore,
+			Cited:           cited[s.Marker],
+		})
+	}
+	return sources
+}
+
+// renderAnswer renders an answer like markdownToHTML2 and turns its
+// citations of the sources into footnote references
+func renderAnswer(markdown string, sources []session.Source) template.HTML {
+	html := string(markdownToHTML2(markdown))
+	if len(sources) == 0 {
+		return template.HTML(html)
+	}
+
+	// Only the text between the tags is rewritten. The sanitized HTML has
+	// its attribute values escaped, so a tag never contains '>'.
+	var out strings.Builder
+	for len(html) > 0 {
+		end := strings.IndexByte(html, '<')
+		if end < 0 {
+			end = len(html)
+		}
+		out.WriteString(citationPattern.ReplaceAllStringFunc(html[:end], func(citation string) string {
+			return footnoteReference(citation, sources)
+		}))
+		html = html[end:]
+
+		if tagEnd := strings.IndexByte(html, '>'); tagEnd >= 0 {
+			out.WriteString(html[:tagEnd+1])
+			html = html[tagEnd+1:]
+		} else {
+			out.WriteString(html)
+			html = ""
+		}
+	}
+	html = out.String()
+	return template.HTML(html)
+}
+
+// footnoteReference returns the footnote reference of a citation, or the
+// citation as is when it cites no source
+func footnoteReference(citation string, sources []session.Source) string {
+	markers := citationMarkers(citation[1:len(citation)-1], sources)
+	if markers == nil {
+		return citation
+	}
+	refs := make([]string, len(markers))
+	for i, marker := range markers {
+		refs[i] = strconv.Itoa(marker)
+	}
+	return `<sup class="citation">[` + strings.Join(refs, ", ") + `]</sup>`
+}
@@ -0,0 +1,133 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	configPkg "github.com/colussim/go-mysql-ai/pkg/config"
+	"github.com/colussim/go-mysql-ai/pkg/interactions"
+	"github.com/colussim/go-mysql-ai/pkg/session"
+	"github.com/colussim/go-mysql-ai/pkg/store"
+)
+
+// Name of the product made of the medications mentioned by the user
+const userMedications = "your medications"
+
+// interactionChecker holds the interaction table loaded by Setup
+var interactionChecker = interactions.NewChecker(nil)
+
+// InteractionWarning is an interaction between two of the medications of an
+// answer, or between one of them and a medication mentioned by the user
+type InteractionWarning struct {
+	IngredientA string    `json:"ingredient_a"`
+	IngredientB string    `json:"ingredient_b"`
+	Severity    string    `json:"severity" description:"minor, moderate, major or contraindicated"`
+	Description string    `json:"description"`
+	Source      string    `json:"source,omitempty"`
+	Products    [2]string `json:"products" description:"Medications that interact, \"your medications\" for the ones mentioned by the user"`
+}
+
+// loadInteractions reads the interaction table of stores that keep one
+func loadInteractions(ctx context.Context) error {
+	interactionStore, ok := vectorStore.(store.InteractionStore)
+	if !ok {
+		return nil
+	}
+	table, err := interactionStore.ListInteractions(ctx)
+	if err != nil {
+		return err
+	}
+	interactionChecker = interactions.NewChecker(table)
+	if interactionChecker.Len() == 0 {
+		configPkg.Log.Warn("⚠️ The interaction table is empty: answers are not checked for drug interactions")
+	}
+	return nil
+}
+
+// medicationIngredients returns the active ingredients of a label. Labels
+// imported without them are searched for the ingredients of the interaction
+// table in their name and product data. The other sections are not
+// searched: warnings name the drugs a medication must not be taken with.
+func medicationIngredients(med store.Medication) []string {
+	if len(med.Ingredients) > 0 {
+		return med.Ingredients
+	}
+	return interactionChecker.Ingredients(med.DrugName + "\n" + med.SPLProductDataElements)
+}
+
+// checkInteractions checks the sources of a conversation against each
+// other and against the ingredients the user mentioned in the conversation
+// or listed in the current medications of the patient profile
+func checkInteractions(conversation *session.Conversation, message string, profile *PatientProfile) []interactions.Warning {
+	var products []interactions.Product
+	for _, source := range conversation.Sources {
+		products = append(products, interactions.Product{
+			Name:        fmt.Sprintf("%s [%d]", source.DrugName, source.Marker),
+			Ingredients: source.Ingredients,
+		})
+	}
+
+	mentioned := []string{message}
+	for _, m := range conversation.Messages {
+		if m.Role == "user" {
+			mentioned = append(mentioned, m.Content)
+		}
+	}
+	taken := interactionChecker.Ingredients(strings.Join(mentioned, "\n"))
+	if profile != nil {
+		for _, ingredient := range profile.ingredients() {
+			if !slices.Contains(taken, ingredient) {
+				taken = append(taken, ingredient)
+			}
+		}
+	}
+	products = append(products, interactions.Product{
+		Name:        userMedications,
+		Ingredients: taken,
+	})
+
+	return interactionChecker.Check(products)
+}
+
+// interactionPrompt asks the model to report the interactions found
+func interactionPrompt(warnings []interactions.Warning) string {
+	if len(warnings) == 0 {
+		return ""
+	}
+	var prompt strings.Builder
+	prompt.WriteString("\n\nDrug interaction warnings. State each of them explicitly in your answer and never recommend these medications together without the warning:\n")
+	for _, w := range warnings {
+		fmt.Fprintf(&prompt, "- %s: %s and %s (%s + %s). %s\n", strings.ToUpper(w.Severity), w.Products[0], w.Products[1], w.IngredientA, w.IngredientB, w.Description)
+	}
+	return prompt.String()
+}
+
+// interactionMarkdown lists the warnings above an answer
+func interactionMarkdown(warnings []interactions.Warning) string {
+	if len(warnings) == 0 {
+		return ""
+	}
+	var text strings.Builder
+	text.WriteString("**⚠️ Drug interactions**\n\n")
+	for _, w := range warnings {
+		fmt.Fprintf(&text, "- **%s**: %s and %s (%s + %s). %s\n", w.Severity, w.Products[0], w.Products[1], w.IngredientA, w.IngredientB, w.Description)
+	}
+	return text.String() + "\n"
+}
+
+func apiWarnings(warnings []interactions.Warning) []InteractionWarning {
+	list := make([]InteractionWarning, 0, len(warnings))
+	for _, w := range warnings {
+		list = append(list, InteractionWarning{
+			IngredientA: w.IngredientA,
+			IngredientB: w.IngredientB,
+			Severity:    w.Severity,
+			Description: w.Description,
+			Source:      w.Source,
+			Products:    w.Products,
+		})
+	}
+	return list
+}
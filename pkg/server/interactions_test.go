@@ -0,0 +1,96 @@
+package server
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/colussim/go-mysql-ai/pkg/interactions"
+	"github.com/colussim/go-mysql-ai/pkg/session"
+	"github.com/colussim/go-mysql-ai/pkg/store"
+)
+
+func TestCheckInteractions(t *testing.T) {
+	saved := interactionChecker
+	interactionChecker = interactions.NewChecker([]store.Interaction{
+		{IngredientA: "aspirin", IngredientB: "ibuprofen", Severity: "moderate", Description: "Ibuprofen reduces the effect of aspirin."},
+		{IngredientA: "ibuprofen", IngredientB: "warfarin", Severity: "major", Description: "Bleeding risk."},
+		{IngredientA: "acetaminophen", IngredientB: "warfarin", Severity: "minor", Description: "May increase the INR."},
+	})
+	t.Cleanup(func() { interactionChecker = saved })
+
+	advil := session.Source{Marker: 1, DrugName: "Advil", Ingredients: []string{"ibuprofen"}}
+	bayer := session.Source{Marker: 2, DrugName: "Bayer", Ingredients: []string{"aspirin"}}
+	tylenol := session.Source{Marker: 3, DrugName: "Tylenol", Ingredients: []string{"acetaminophen"}}
+
+	tests := []struct {
+		name     string
+		sources  []session.Source
+		messages []session.Message
+		message  string
+		profile  *PatientProfile
+		want     []string
+	}{
+		{
+			name:    "sources against each other",
+			sources: []session.Source{advil, bayer},
+			message: "What can I take for a headache?",
+			want:    []string{"moderate: Advil [1] + Bayer [2]"},
+		},
+		{
+			name:    "medication mentioned in the question",
+			sources: []session.Source{advil, tylenol},
+			message: "I take Warfarin, what can I take for a headache?",
+			want: []string{
+				"major: Advil [1] + your medications",
+				"minor: Tylenol [3] + your medications",
+			},
+		},
+		{
+			name:     "medication mentioned earlier in the conversation",
+			sources:  []session.Source{advil},
+			messages: []session.Message{{Role: "user", Content: "I am on warfarin"}, {Role: "assistant", Content: "Noted: aspirin is not advised"}},
+			message:  "Which one is the strongest?",
+			want:     []string{"major: Advil [1] + your medications"},
+		},
+		{
+			name:    "medications named by the assistant are not the user's",
+			sources: []session.Source{tylenol},
+			messages: []session.Message{
+				{Role: "assistant", Content: "Warfarin interacts with many medications"},
+			},
+			message: "Is Tylenol fine?",
+			want:    []string{},
+		},
+		{
+			name:    "current medications of the profile",
+			sources: []session.Source{advil},
+			message: "What can I take for a headache?",
+			profile: &PatientProfile{CurrentMedications: []string{"Warfarin 5 mg"}},
+			want:    []string{"major: Advil [1] + your medications"},
+		},
+		{
+			name:    "mentioned in the question and in the profile",
+			sources: []session.Source{advil},
+			message: "I take warfarin",
+			profile: &PatientProfile{CurrentMedications: []string{"warfarin"}},
+			want:    []string{"major: Advil [1] + your medications"},
+		},
+		{
+			name:    "no sources",
+			message: "I take warfarin and aspirin",
+			want:    []string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conversation := &session.Conversation{Sources: tt.sources, Messages: tt.messages}
+			got := []string{}
+			for _, w := range checkInteractions(conversation, tt.message, tt.profile) {
+				got = append(got, fmt.Sprintf("%s: %s + %s", w.Severity, w.Products[0], w.Products[1]))
+			}
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("checkInteractions() =\n%q\nwant\n%q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,160 @@
+package server
+
+import (
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+var (
+	openAPIOnce     sync.Once
+	openAPIDocument map[string]any
+)
+
+// openAPIHandler serves the OpenAPI 3 document generated from apiRoutes
+func openAPIHandler(w http.ResponseWriter, r *http.Request) {
+	openAPIOnce.Do(func() {
+		openAPIDocument = buildOpenAPI(apiRoutes())
+	})
+	sendAPIJSON(w, http.StatusOK, openAPIDocument)
+}
+
+// buildOpenAPI describes the routes, their parameters and the JSON schemas
+// of their bodies, read from the json and description tags of the types
+func buildOpenAPI(routes []apiRoute) map[string]any {
+	schemas := make(map[string]any)
+	paths := make(map[string]any)
+
+	errorContent := jsonContent(schemaOf(reflect.TypeOf(ErrorResponse{}), schemas))
+
+	for _, route := range routes {
+		operation := map[string]any{
+			"operationId": route.operationID,
+			"summary":     route.summary,
+		}
+
+		var parameters []any
+		for _, parameter := range route.parameters {
+			schema := map[string]any{"type": parameter.kind}
+			in := "path"
+			if parameter.query {
+				schema = map[string]any{"type": "array", "items": schema}
+				in = "query"
+			}
+			parameters = append(parameters, map[string]any{
+				"name":        parameter.name,
+				"in":          in,
+				"required":    !parameter.query,
+				"description": parameter.description,
+				"schema":      schema,
+			})
+		}
+		if parameters != nil {
+			operation["parameters"] = parameters
+		}
+
+		if route.request != nil {
+			operation["requestBody"] = map[string]any{
+				"required": true,
+				"content":  jsonContent(schemaOf(reflect.TypeOf(route.request), schemas)),
+			}
+		}
+
+		responseSchema := map[string]any{"type": "object"}
+		if route.response != nil {
+			responseSchema = schemaOf(reflect.TypeOf(route.response), schemas)
+		}
+		responses := map[string]any{
+			"200": map[string]any{"description": "OK", "content": jsonContent(responseSchema)},
+		}
+		for _, status := range route.errors {
+			responses[strconv.Itoa(status)] = map[string]any{
+				"description": http.StatusText(status),
+				"content":     errorContent,
+			}
+		}
+		operation["responses"] = responses
+
+		item, ok := paths[route.path].(map[string]any)
+		if !ok {
+			item = make(map[string]any)
+			paths[route.path] = item
+		}
+		item[strings.ToLower(route.method)] = operation
+	}
+
+	return map[string]any{
+		"openapi": "3.0.3",
+		"info": map[string]any{
+			"title":       "go-mysql-ai API",
+			"description": "Medication recommendations grounded on OpenFDA drug labels. Errors are returned as an ErrorResponse.",
+			"version":     "1",
+		},
+		"paths":      paths,
+		"components": map[string]any{"schemas": schemas},
+	}
+}
+
+func jsonContent(schema map[string]any) map[string]any {
+	return map[string]any{"application/json": map[string]any{"schema": schema}}
+}
+
+// schemaOf returns the schema of a Go type. Structs are added to schemas
+// under their name and referenced.
+func schemaOf(t reflect.Type, schemas map[string]any) map[string]any {
+	switch t.Kind() {
+	case reflect.Pointer:
+		return map[string]any{"allOf": []any{schemaOf(t.Elem(), schemas)}, "nullable": true}
+	case reflect.Slice, reflect.Array:
+		return map[string]any{"type": "array", "items": schemaOf(t.Elem(), schemas)}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": schemaOf(t.Elem(), schemas)}
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.Struct:
+		ref := map[string]any{"$ref": "#/components/schemas/" + t.Name()}
+		if _, ok := schemas[t.Name()]; ok {
+			return ref
+		}
+		// Registered before its fields so recursive types terminate
+		schema := map[string]any{"type": "object"}
+		schemas[t.Name()] = schema
+
+		properties := make(map[string]any)
+		var required []string
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			name, options, _ := strings.Cut(field.Tag.Get("json"), ",")
+			if !field.IsExported() || name == "-" {
+				continue
+			}
+			if name == "" {
+				name = field.Name
+			}
+			property := schemaOf(field.Type, schemas)
+			if description := field.Tag.Get("description"); description != "" {
+				property["description"] = description
+			}
+			properties[name] = property
+			if !strings.Contains(options, "omitempty") {
+				required = append(required, name)
+			}
+		}
+		schema["properties"] = properties
+		if required != nil {
+			schema["required"] = required
+		}
+		return ref
+	default:
+		return map[string]any{}
+	}
+}
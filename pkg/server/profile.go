@@ -0,0 +1,332 @@
+package server
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/colussim/go-mysql-ai/pkg/session"
+	"github.com/colussim/go-mysql-ai/pkg/store"
+)
+
+// PatientProfile describes the patient a question is about. Candidates
+// that do not suit the patient are excluded or given cautions before the
+// model is prompted.
+type PatientProfile struct {
+	AgeBand            string   `json:"age_band,omitempty" description:"infant (under 2), child (2 to 11), adolescent (12 to 17), adult or senior (65 and over)"`
+	Pregnant           bool     `json:"pregnant,omitempty"`
+	Breastfeeding      bool     `json:"breastfeeding,omitempty"`
+	Allergies          []string `json:"allergies,omitempty" description:"Known allergies and intolerances: active ingredients, excipients (lactose...) or drug classes (nsaid, salicylate)"`
+	CurrentMedications []string `json:"current_medications,omitempty" description:"Medications or active ingredients the patient takes, checked for interactions and duplicates"`
+}
+
+// Exclusion is a retrieved medication left out of the answer for the patient
+type Exclusion struct {
+	ID       int    `json:"id" description:"ID of the medication"`
+	DrugName string `json:"drug_name"`
+	Reason   string `json:"reason"`
+}
+
+// Age bands with the age, in years, of their youngest and oldest patients
+var ageBands = map[string][2]int{
+	"infant":     {0, 1},
+	"child":      {2, 11},
+	"adolescent": {12, 17},
+	"adult":      {18, 64},
+	"senior":     {65, 120},
+}
+
+// Drug classes that can be given as allergies, with their ingredients
+var drugClasses = map[string][]string{
+	"nsaid":      {"aspirin", "ibuprofen", "naproxen", "ketoprofen", "diclofenac", "celecoxib", "meloxicam", "magnesium salicylate"},
+	"salicylate": {"aspirin", "bismuth subsalicylate", "magnesium salicylate", "salsalate"},
+}
+
+// Other names of the drug classes, in the singular and with spaces for
+// hyphens
+var drugClassAliases = map[string]string{
+	"anti inflammatory":               "nsaid",
+	"antiinflammatory":                "nsaid",
+	"non steroidal anti inflammatory": "nsaid",
+	"nonsteroidal anti inflammatory":  "nsaid",
+	"nonsteroidal antiinflammatory":   "nsaid",
+}
+
+// Nouns following a drug class: "anti-inflammatory drugs"
+var drugClassNoun = regexp.MustCompile(`\s+(?:drug|medication|medicine)s?$`)
+
+// Age limits of the directions of OTC labels: "children under 12 years:
+// ask a doctor", "do not use in children under 2 years of age"
+var (
+	labelAgeDirection = regexp.MustCompile(`(?i)children (?:under|younger than) (\d+) years?(?: of age)?\s*[:,-]?\s*(do not use|ask a doctor)`)
+	labelAgeDoNotUse  = regexp.MustCompile(`(?i)do not (?:use|give)[^.]{0,40}?(?:children|child) (?:under|younger than) (\d+) years?`)
+)
+
+// Validate normalizes the profile and checks its age band
+func (p *PatientProfile) Validate() error {
+	p.AgeBand = strings.ToLower(strings.TrimSpace(p.AgeBand))
+	if _, ok := ageBands[p.AgeBand]; p.AgeBand != "" && !ok {
+		return fmt.Errorf("❌ Unknown age band %q, expected infant, child, adolescent, adult or senior", p.AgeBand)
+	}
+	return nil
+}
+
+// profileFromForm reads a profile from the parameters of the chat page:
+// age_band, pregnant, breastfeeding, and the comma separated allergies and
+// current_medications. It returns nil when none is set.
+func profileFromForm(values url.Values) (*PatientProfile, error) {
+	list := func(name string) []string {
+		var items []string
+		for _, item := range strings.Split(values.Get(name), ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		return items
+	}
+	flag := func(name string) bool {
+		set, _ := strconv.ParseBool(values.Get(name))
+		return set
+	}
+
+	profile := &PatientProfile{
+		AgeBand:            values.Get("age_band"),
+		Pregnant:           flag("pregnant"),
+		Breastfeeding:      flag("breastfeeding"),
+		Allergies:          list("allergies"),
+		CurrentMedications: list("current_medications"),
+	}
+	if err := profile.Validate(); err != nil {
+		return nil, err
+	}
+	if profile.AgeBand == "" && !profile.Pregnant && !profile.Breastfeeding && profile.Allergies == nil && profile.CurrentMedications == nil {
+		return nil, nil
+	}
+	return profile, nil
+}
+
+// containsWord tells whether a label section names a substance
+func containsWord(text, name string) bool {
+	return regexp.MustCompile(`(?i)\b` + regexp.QuoteMeta(name) + `\b`).MatchString(text)
+}
+
+// drugClass returns the drug class an allergy names, in the plural or by
+// another name ("NSAIDs", "anti-inflammatories"), or an empty name
+func drugClass(allergy string) string {
+	name := strings.ReplaceAll(store.CanonicalIngredient(allergy), "-", " ")
+	name = strings.TrimSuffix(name, " s")
+	name = drugClassNoun.ReplaceAllString(name, "")
+
+	singular := strings.TrimSuffix(name, "s")
+	if strings.HasSuffix(name, "ies") {
+		singular = strings.TrimSuffix(name, "ies") + "y"
+	}
+	for _, candidate := range []string{name, singular} {
+		if _, ok := drugClasses[candidate]; ok {
+			return candidate
+		}
+		if class, ok := drugClassAliases[candidate]; ok {
+			return class
+		}
+	}
+	return ""
+}
+
+// classIngredients returns the ingredients of the drug classes named in
+// the name, purpose, indications or product data of a label
+func classIngredients(med store.Medication) []string {
+	text := strings.Join([]string{med.DrugName, med.Purpose, med.Indications, med.SPLProductDataElements}, "\n")
+	var found []string
+	for _, class := range []string{"nsaid", "salicylate"} {
+		for _, ingredient := range drugClasses[class] {
+			if !slices.Contains(found, ingredient) && containsWord(text, ingredient) {
+				found = append(found, ingredient)
+			}
+		}
+	}
+	return found
+}
+
+// isNSAID tells whether an ingredient is a non-steroidal anti-inflammatory
+func isNSAID(ingredient string) bool {
+	return slices.Contains(drugClasses["nsaid"], ingredient)
+}
+
+// assessMedication returns why a medication must not be given to the
+// patient, or the cautions to give with it
+func assessMedication(profile *PatientProfile, med store.Medication) (reason string, cautions []string) {
+	ingredients := medicationIngredients(med)
+	// Labels imported without their active ingredients are also searched
+	// for the ingredients of the drug classes, which the interaction table
+	// may not list, so the class allergies and NSAID cautions still apply
+	if len(med.Ingredients) == 0 {
+		for _, ingredient := range classIngredients(med) {
+			if !slices.Contains(ingredients, ingredient) {
+				ingredients = append(ingredients, ingredient)
+			}
+		}
+	}
+
+	// Allergies and intolerances, to an ingredient, a class or an excipient
+	for _, allergy := range profile.Allergies {
+		name := store.CanonicalIngredient(allergy)
+		if name == "" {
+			continue
+		}
+		if slices.Contains(ingredients, name) {
+			return fmt.Sprintf("contains %s, listed in the allergies", name), nil
+		}
+		if class := drugClass(allergy); class != "" {
+			for _, ingredient := range ingredients {
+				if slices.Contains(drugClasses[class], ingredient) {
+					return fmt.Sprintf("contains %s (%s), listed in the allergies", ingredient, strings.ToUpper(class)), nil
+				}
+			}
+		}
+		if containsWord(med.InactiveIngredient, name) {
+			return fmt.Sprintf("contains %s as an inactive ingredient, listed in the intolerances", name), nil
+		}
+	}
+
+	// Directions of the label for the age of the patient
+	if band, ok := ageBands[profile.AgeBand]; ok {
+		for _, match := range labelAgeDirection.FindAllStringSubmatch(med.Dosage, -1) {
+			limit, _ := strconv.Atoi(match[1])
+			if band[1] >= limit {
+				continue
+			}
+			if strings.EqualFold(match[2], "do not use") {
+				return fmt.Sprintf("the label says not to use it in children under %d years", limit), nil
+			}
+			cautions = append(cautions, fmt.Sprintf("Children under %d years: ask a doctor before use", limit))
+		}
+		for _, text := range []string{med.Dosage, med.Warnings} {
+			for _, match := range labelAgeDoNotUse.FindAllStringSubmatch(text, -1) {
+				if limit, _ := strconv.Atoi(match[1]); band[1] < limit {
+					return fmt.Sprintf("the label says not to use it in children under %d years", limit), nil
+				}
+			}
+		}
+
+		if band[1] < 18 && (slices.Contains(ingredients, "aspirin") || strings.Contains(strings.ToLower(med.Warnings), "reye")) {
+			cautions = append(cautions, "Reye's syndrome: children and teenagers recovering from chicken pox or flu-like symptoms must not use it")
+		}
+		if profile.AgeBand == "senior" && slices.ContainsFunc(ingredients, isNSAID) {
+			cautions = append(cautions, "NSAID: the risk of stomach bleeding is higher from age 60")
+		}
+	}
+
+	// Pregnancy and breast-feeding
+	if profile.Pregnant && slices.ContainsFunc(ingredients, isNSAID) {
+		cautions = append(cautions, "NSAID during pregnancy: ask a doctor before use, and do not use it from 20 weeks of pregnancy, especially in the last 3 months, unless a doctor says so")
+	}
+	if (profile.Pregnant || profile.Breastfeeding) && strings.TrimSpace(med.PregnancyOrBreastFeeding) != "" {
+		cautions = append(cautions, "Label, pregnancy or breast-feeding: "+strings.TrimSpace(med.PregnancyOrBreastFeeding))
+	}
+
+	// Duplicates of what the patient already takes
+	current := profile.ingredients()
+	for _, ingredient := range ingredients {
+		if slices.Contains(current, ingredient) {
+			cautions = append(cautions, fmt.Sprintf("The patient already takes %s: do not combine, risk of overdose", ingredient))
+		}
+	}
+
+	return "", cautions
+}
+
+// ingredients returns the active ingredients of the current medications:
+// the canonical names given and the ingredients of the interaction table
+// found in them, as in "Advil (ibuprofen)"
+func (p *PatientProfile) ingredients() []string {
+	found := store.CanonicalIngredients(p.CurrentMedications)
+	for _, ingredient := range interactionChecker.Ingredients(strings.Join(p.CurrentMedications, "\n")) {
+		if !slices.Contains(found, ingredient) {
+			found = append(found, ingredient)
+		}
+	}
+	return found
+}
+
+// applyProfile removes the medications that do not suit the patient, with
+// the reason, and sets the cautions of the others
+func applyProfile(profile *PatientProfile, candidates []store.Medication) ([]store.Medication, []Exclusion) {
+	if profile == nil {
+		return candidates, nil
+	}
+	var kept []store.Medication
+	var excluded []Exclusion
+	for _, med := range candidates {
+		reason, cautions := assessMedication(profile, med)
+		if reason != "" {
+			excluded = append(excluded, Exclusion{ID: med.ID, DrugName: med.DrugName, Reason: reason})
+			continue
+		}
+		med.Cautions = cautions
+		kept = append(kept, med)
+	}
+	return kept, excluded
+}
+
+// profilePrompt describes the patient and the excluded medications to the model
+func profilePrompt(profile *PatientProfile, excluded []Exclusion) string {
+	if profile == nil {
+		return ""
+	}
+	var facts []string
+	if profile.AgeBand != "" {
+		facts = append(facts, "age band: "+profile.AgeBand)
+	}
+	if profile.Pregnant {
+		facts = append(facts, "pregnant")
+	}
+	if profile.Breastfeeding {
+		facts = append(facts, "breastfeeding")
+	}
+	if len(profile.Allergies) > 0 {
+		facts = append(facts, "allergies and intolerances: "+strings.Join(profile.Allergies, ", "))
+	}
+	if len(profile.CurrentMedications) > 0 {
+		facts = append(facts, "current medications: "+strings.Join(profile.CurrentMedications, ", "))
+	}
+
+	prompt := "\nPatient: " + strings.Join(facts, "; ") + ". Give the cautions listed for each medication in your answer.\n"
+	if len(excluded) > 0 {
+		prompt += "Do not recommend these medications, unsuitable for this patient:\n"
+		for _, e := range excluded {
+			prompt += fmt.Sprintf("- %s: %s\n", e.DrugName, e.Reason)
+		}
+	}
+	return prompt
+}
+
+// cautionMarkdown lists the cautions for the patient above an answer
+func cautionMarkdown(sources []session.Source) string {
+	var text strings.Builder
+	for _, source := range sources {
+		for _, caution := range source.Cautions {
+			fmt.Fprintf(&text, "- **%s** [%d]: %s\n", source.DrugName, source.Marker, caution)
+		}
+	}
+	if text.Len() == 0 {
+		return ""
+	}
+	return "**⚠️ Cautions for this patient**\n\n" + text.String() + "\n"
+}
+
+// exclusionMarkdown lists the medications excluded for the patient below an
+// answer
+func exclusionMarkdown(excluded []Exclusion) string {
+	if len(excluded) == 0 {
+		return ""
+	}
+	var text strings.Builder
+	text.WriteString("\n\n**🚫 Not suitable for this patient**\n\n")
+	for _, e := range excluded {
+		fmt.Fprintf(&text, "- %s: %s\n", e.DrugName, e.Reason)
+	}
+	return text.String()
+}
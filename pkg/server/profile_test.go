@@ -0,0 +1,104 @@
+package server
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/colussim/go-mysql-ai/pkg/store"
+)
+
+func TestDrugClass(t *testing.T) {
+	tests := []struct {
+		allergy string
+		want    string
+	}{
+		{"NSAID", "nsaid"},
+		{"NSAIDs", "nsaid"},
+		{"nsaids", "nsaid"},
+		{"NSAID's", "nsaid"},
+		{"Anti-inflammatories", "nsaid"},
+		{"anti-inflammatory drugs", "nsaid"},
+		{"Non-steroidal anti-inflammatory drugs", "nsaid"},
+		{"nonsteroidal anti-inflammatory medications", "nsaid"},
+		{"Salicylates", "salicylate"},
+		{"salicylate", "salicylate"},
+		{"aspirin", ""},
+		{"penicillin", ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := drugClass(tt.allergy); got != tt.want {
+			t.Errorf("drugClass(%q) = %q, want %q", tt.allergy, got, tt.want)
+		}
+	}
+}
+
+func TestAssessMedicationAllergies(t *testing.T) {
+	advil := store.Medication{DrugName: "Advil", Ingredients: []string{"ibuprofen"}}
+	bayer := store.Medication{DrugName: "Bayer", Ingredients: []string{"aspirin"}}
+	tylenol := store.Medication{DrugName: "Tylenol", Ingredients: []string{"acetaminophen"}, InactiveIngredient: "corn starch, lactose monohydrate"}
+	// Imported without its active ingredients
+	motrin := store.Medication{DrugName: "Motrin IB", Purpose: "Pain reliever/fever reducer", SPLProductDataElements: "Motrin IB Ibuprofen IBUPROFEN 200 mg"}
+
+	tests := []struct {
+		name      string
+		allergies []string
+		med       store.Medication
+		want      string
+	}{
+		{"ingredient", []string{"Ibuprofen"}, advil, "contains ibuprofen, listed in the allergies"},
+		{"class", []string{"NSAID"}, advil, "contains ibuprofen (NSAID), listed in the allergies"},
+		{"plural class", []string{"NSAIDs"}, advil, "contains ibuprofen (NSAID), listed in the allergies"},
+		{"class alias", []string{"anti-inflammatories"}, bayer, "contains aspirin (NSAID), listed in the allergies"},
+		{"plural salicylates", []string{"Salicylates"}, bayer, "contains aspirin (SALICYLATE), listed in the allergies"},
+		{"excipient", []string{"lactose"}, tylenol, "contains lactose as an inactive ingredient, listed in the intolerances"},
+		{"class without the ingredient", []string{"NSAIDs"}, tylenol, ""},
+		{"class named in the product data", []string{"NSAIDs"}, motrin, "contains ibuprofen (NSAID), listed in the allergies"},
+		{"unrelated allergy", []string{"penicillin"}, advil, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			profile := &PatientProfile{Allergies: tt.allergies}
+			if reason, _ := assessMedication(profile, tt.med); reason != tt.want {
+				t.Errorf("assessMedication() = %q, want %q", reason, tt.want)
+			}
+		})
+	}
+}
+
+func TestAssessMedicationNSAIDCautions(t *testing.T) {
+	advil := store.Medication{DrugName: "Advil", Ingredients: []string{"ibuprofen"}}
+	tylenol := store.Medication{DrugName: "Tylenol", Ingredients: []string{"acetaminophen"}}
+	// Imported without their active ingredients
+	aleve := store.Medication{DrugName: "Aleve", Indications: "temporarily relieves minor aches and pains", SPLProductDataElements: "Aleve Naproxen Sodium NAPROXEN 220 mg"}
+	unknown := store.Medication{DrugName: "Pain Relief", Purpose: "Pain reliever"}
+
+	tests := []struct {
+		name    string
+		profile PatientProfile
+		med     store.Medication
+		want    []string
+	}{
+		{"pregnant", PatientProfile{Pregnant: true}, advil, []string{"NSAID during pregnancy"}},
+		{"pregnant without ingredients", PatientProfile{Pregnant: true}, aleve, []string{"NSAID during pregnancy"}},
+		{"senior without ingredients", PatientProfile{AgeBand: "senior"}, aleve, []string{"NSAID"}},
+		{"pregnant, not an NSAID", PatientProfile{Pregnant: true}, tylenol, nil},
+		{"pregnant, ingredients unknown", PatientProfile{Pregnant: true}, unknown, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reason, cautions := assessMedication(&tt.profile, tt.med)
+			if reason != "" {
+				t.Fatalf("assessMedication() excluded it: %s", reason)
+			}
+			var got []string
+			for _, caution := range cautions {
+				got = append(got, strings.SplitN(caution, ":", 2)[0])
+			}
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("cautions = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
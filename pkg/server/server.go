@@ -0,0 +1,823 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"log"
+	"net"
+	"net/http"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	configPkg "github.com/colussim/go-mysql-ai/pkg/config"
+	"github.com/colussim/go-mysql-ai/pkg/embedding"
+	"github.com/colussim/go-mysql-ai/pkg/interactions"
+	"github.com/colussim/go-mysql-ai/pkg/llm"
+	"github.com/colussim/go-mysql-ai/pkg/sanitize"
+	"github.com/colussim/go-mysql-ai/pkg/session"
+	"github.com/colussim/go-mysql-ai/pkg/store"
+	"github.com/colussim/go-mysql-ai/pkg/symptoms"
+	md "github.com/gomarkdown/markdown"
+	mdhtml "github.com/gomarkdown/markdown/html"
+	"github.com/gomarkdown/markdown/parser"
+)
+
+type TemplateData struct {
+	Messages string
+}
+
+type Response struct {
+	Response string `json:"response"`
+}
+
+type Response1 struct {
+	Response template.HTML `json:"response"`
+}
+
+// StreamEvent is a server-sent event of /chat/stream: start carries the
+// blocks shown above the answer, chunk the text generated, and done the
+// rendered answer with its sources
+type StreamEvent struct {
+	Delta string        `json:"delta,omitempty"`
+	HTML  template.HTML `json:"html,omitempty"`
+	Text  string        `json:"text,omitempty"`
+	Error string        `json:"error,omitempty"`
+	// Sources, interactions and exclusions of the answer, sent with the
+	// done event
+	Sources      []Source             `json:"sources,omitempty"`
+	Interactions []InteractionWarning `json:"interactions,omitempty"`
+	Exclusions   []Exclusion          `json:"exclusions,omitempty"`
+	// Urgent-care guidance given instead of an answer
+	Triage *Triage `json:"triage,omitempty"`
+	// Pathologies whose symptoms the question describes, with the done
+	// event and with a clarifying question
+	Differential []symptoms.Candidate `json:"differential,omitempty"`
+}
+
+// Default minimum cosine similarity a pathology or medication must reach
+// before the question is considered understood
+const defaultThreshold = 0.5
+const defaultLimit = 3
+
+// Default size, in estimated tokens, of the requests sent to the model
+const defaultTokenBudget = 4096
+
+const sessionCookie = "session_id"
+
+// Main html page: index.html, parsed by Setup
+var tpl *template.Template
+
+var vectorStore store.VectorStore
+var generator llm.Provider
+var embeddings *embedding.Service
+var sessions session.Store
+var pathology *configPkg.Pathology
+var config *configPkg.Config
+
+// markdownToHTML2 renders the model answer. Raw HTML written by the model is
+// dropped and links are restricted to safe protocols, then the result goes
+// through the sanitize.Markdown allowlist, since it is inserted as is in the
+// chat page.
+func markdownToHTML2(markdown string) template.HTML {
+	extensions := parser.CommonExtensions | parser.AutoHeadingIDs
+	p := parser.NewWithExtensions(extensions)
+	renderer := mdhtml.NewRenderer(mdhtml.RendererOptions{
+		Flags: mdhtml.CommonFlags | mdhtml.SkipHTML | mdhtml.Safelink,
+	})
+	html := md.ToHTML([]byte(markdown), p, renderer)
+	return template.HTML(sanitize.Markdown.Sanitize(string(html)))
+}
+
+func sendJSONResponse(w http.ResponseWriter, response Response) {
+	w.Header().Set("Content-Type", "application/json")
+
+	configPkg.InitLogger()
+	err := json.NewEncoder(w).Encode(response)
+	if err != nil {
+		configPkg.Log.Errorf("❌ Error encoding response: %v", err)
+	}
+}
+
+func sendJSONResponse2(w http.ResponseWriter, response Response1) {
+	w.Header().Set("Content-Type", "application/json")
+	configPkg.InitLogger()
+	err := json.NewEncoder(w).Encode(response)
+	if err != nil {
+		configPkg.Log.Errorf("❌ Error encoding response: %v", err)
+	}
+}
+
+func indexHandler(w http.ResponseWriter, r *http.Request) {
+	tpl.Execute(w, nil)
+}
+
+func chatHandler(w http.ResponseWriter, r *http.Request) {
+	r.ParseForm()
+	message := r.Form.Get("message")
+	ctx := r.Context()
+
+	profile, err := profileFromForm(r.Form)
+	if err != nil {
+		http.Error(w, "Invalid patient profile: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	conversation, err := getConversation(w, r)
+	if err != nil {
+		log.Printf("Error loading conversation: %v", err)
+		http.Error(w, "Error loading conversation: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// Red-flag symptoms get urgent-care guidance, without medications
+	queryEmbedding, urgent, err := triageAndEmbed(ctx, conversation, message, profile)
+	if err != nil {
+		log.Printf("Error generating embedding: %v", err)
+		http.Error(w, "Error generating embedding: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if urgent != nil {
+		sendJSONResponse2(w, Response1{Response: renderAnswer(triageMarkdown(urgent), nil)})
+		return
+	}
+
+	inferred, err := inferPathology(ctx, conversation, message, queryEmbedding)
+	if err != nil {
+		log.Printf("Error matching pathology: %v", err)
+		http.Error(w, "Error matching pathology: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if inferred.clarification != "" {
+		if err := askClarification(ctx, conversation, message, inferred); err != nil {
+			http.Error(w, "Error saving conversation: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		sendJSONResponse2(w, Response1{Response: renderAnswer(inferred.clarification+symptomMarkdown(inferred.differential), nil)})
+		return
+	}
+	match := inferred.match
+	if match == nil && conversation.Context == "" {
+		response := Response{Response: unsupportedPathologyMessage()}
+		sendJSONResponse(w, response)
+		return
+	}
+
+	result, err := generateResponse(ctx, conversation, match, queryEmbedding, message, profile, nil)
+	if err != nil {
+		log.Printf("Error generating response: %v", err)
+		http.Error(w, "Error generating response: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	responseMessage := result.Text
+
+	htmlResponse := renderAnswer(answerMarkdown(conversation, result, inferred.differential), conversation.Sources)
+
+	response := Response1{
+		Response: htmlResponse,
+	}
+	sendJSONResponse2(w, response)
+
+	log.Printf("Response sent to client for pathology '%s': %s", conversation.Pathology, responseMessage)
+
+}
+
+// chatStreamHandler answers like chatHandler but forwards the answer to the
+// browser as server-sent events while the model generates it. Generation
+// stops as soon as the client disconnects.
+func chatStreamHandler(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "Streaming is not supported", http.StatusInternalServerError)
+		return
+	}
+
+	message := r.URL.Query().Get("message")
+	ctx := r.Context()
+
+	profile, err := profileFromForm(r.URL.Query())
+	if err != nil {
+		http.Error(w, "Invalid patient profile: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// The session cookie must be set before the stream starts
+	conversation, err := getConversation(w, r)
+	if err != nil {
+		log.Printf("Error loading conversation: %v", err)
+		http.Error(w, "Error loading conversation: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	fail := func(step string, err error) {
+		if ctx.Err() != nil {
+			log.Printf("Client disconnected during %s", step)
+			return
+		}
+		log.Printf("Error %s: %v", step, err)
+		sendEvent(w, "error", StreamEvent{Error: "Error " + step + ": " + err.Error()})
+	}
+
+	// Red-flag symptoms get urgent-care guidance, without medications
+	queryEmbedding, urgent, err := triageAndEmbed(ctx, conversation, message, profile)
+	if err != nil {
+		fail("generating embedding", err)
+		return
+	}
+	if urgent != nil {
+		sendEvent(w, "done", StreamEvent{HTML: renderAnswer(triageMarkdown(urgent), nil), Triage: urgent})
+		return
+	}
+
+	inferred, err := inferPathology(ctx, conversation, message, queryEmbedding)
+	if err != nil {
+		fail("matching pathology", err)
+		return
+	}
+	if inferred.clarification != "" {
+		if err := askClarification(ctx, conversation, message, inferred); err != nil {
+			fail("saving conversation", err)
+			return
+		}
+		sendEvent(w, "done", StreamEvent{Text: inferred.clarification, Differential: inferred.differential})
+		return
+	}
+	match := inferred.match
+	if match == nil && conversation.Context == "" {
+		sendEvent(w, "done", StreamEvent{Text: unsupportedPathologyMessage()})
+		return
+	}
+
+	started := false
+	result, err := generateResponse(ctx, conversation, match, queryEmbedding, message, profile, func(chunk string) error {
+		// The cautions for the patient and the drug interactions are sent
+		// once before the answer, so no medication is shown without them.
+		// The sources and the question do not change during the
+		// generation. The chunks only carry the text generated: the
+		// answer is rendered once, when done.
+		if !started {
+			started = true
+			warnings := interactionMarkdown(checkInteractions(conversation, message, profile))
+			if err := sendEvent(w, "start", StreamEvent{HTML: renderAnswer(cautionMarkdown(conversation.Sources)+warnings, conversation.Sources)}); err != nil {
+				return err
+			}
+		}
+		return sendEvent(w, "chunk", StreamEvent{Delta: chunk})
+	})
+	if err != nil {
+		fail("generating response", err)
+		return
+	}
+	responseMessage := result.Text
+
+	sendEvent(w, "done", StreamEvent{
+		HTML:         renderAnswer(cautionMarkdown(conversation.Sources)+interactionMarkdown(result.Warnings)+responseMessage, conversation.Sources),
+		Sources:      answerSources(conversation, responseMessage),
+		Interactions: apiWarnings(result.Warnings),
+		Exclusions:   result.Exclusions,
+		Differential: inferred.differential,
+	})
+
+	log.Printf("Response streamed to client for pathology '%s': %s", conversation.Pathology, responseMessage)
+}
+
+// sendEvent writes one server-sent event and flushes it to the client
+func sendEvent(w http.ResponseWriter, event string, data StreamEvent) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("❌ Error encoding event: %w", err)
+	}
+	if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, payload); err != nil {
+		return fmt.Errorf("❌ Error writing event: %w", err)
+	}
+	w.(http.Flusher).Flush()
+	return nil
+}
+
+// unsupportedPathologyMessage is the answer given when the question matches no pathology
+func unsupportedPathologyMessage() string {
+	pathologiesList := make([]string, 0, len(pathology.Pathologies))
+	for name := range pathology.Pathologies {
+		pathologiesList = append(pathologiesList, name)
+	}
+	sort.Strings(pathologiesList)
+	return "I did not recognize any pathology in your message. The pathologies supported are:" + strings.Join(pathologiesList, ", ")
+}
+
+// getConversation returns the conversation of the session cookie, starting a
+// new one (and setting the cookie) when there is none or it has ended
+func getConversation(w http.ResponseWriter, r *http.Request) (*session.Conversation, error) {
+	if cookie, err := r.Cookie(sessionCookie); err == nil {
+		conversation, err := sessions.Get(r.Context(), cookie.Value)
+		if err != nil {
+			return nil, err
+		}
+		if conversation != nil {
+			return conversation, nil
+		}
+	}
+
+	conversation, err := sessions.Create(r.Context())
+	if err != nil {
+		return nil, err
+	}
+	http.SetCookie(w, &http.Cookie{
+		Name:     sessionCookie,
+		Value:    conversation.ID,
+		Path:     "/",
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+	return conversation, nil
+}
+
+// clearHandler ends the conversation of the session cookie
+func clearHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if cookie, err := r.Cookie(sessionCookie); err == nil {
+		if err := sessions.End(r.Context(), cookie.Value); err != nil {
+			log.Printf("Error ending conversation: %v", err)
+			http.Error(w, "Error ending conversation: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     sessionCookie,
+		Value:    "",
+		Path:     "/",
+		MaxAge:   -1,
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// searchThreshold returns the configured confidence threshold
+func searchThreshold() float64 {
+	if config.Search.Threshold > 0 {
+		return config.Search.Threshold
+	}
+	return defaultThreshold
+}
+
+// searchLimit returns the configured number of medications given to the model
+func searchLimit() int {
+	if config.Search.Limit > 0 {
+		return config.Search.Limit
+	}
+	return defaultLimit
+}
+
+// matchPathology returns the pathology the question is about, or nil when
+// neither a pathology nor a medication reaches the confidence threshold.
+// Medications are searched directly when no pathology is close enough, so a
+// question about a drug still resolves to the pathology it is stored under.
+func matchPathology(ctx context.Context, queryEmbedding []float64) (*store.Pathology, error) {
+	threshold := searchThreshold()
+
+	matches, err := vectorStore.SearchPathologies(ctx, queryEmbedding, 1)
+	if err != nil {
+		return nil, err
+	}
+	if len(matches) > 0 && matches[0].SimilarityScore >= threshold {
+		return &matches[0], nil
+	}
+
+	medications, _, err := findSimilarMedications(ctx, 0, 1, queryEmbedding, nil)
+	if err != nil {
+		return nil, err
+	}
+	if len(medications) > 0 && medications[0].SimilarityScore >= threshold {
+		med := medications[0]
+		return &store.Pathology{ID: med.PathologyID, Name: med.Pathology, SimilarityScore: med.SimilarityScore}, nil
+	}
+
+	return nil, nil
+}
+
+// generatedAnswer is an answer with what was found while preparing it
+type generatedAnswer struct {
+	Text       string
+	Warnings   []interactions.Warning
+	Exclusions []Exclusion
+}
+
+// answerMarkdown writes an answer as the chat page shows it: the cautions
+// for the patient and the interactions above it, the excluded medications
+// and the matched symptoms below
+func answerMarkdown(conversation *session.Conversation, result *generatedAnswer, differential []symptoms.Candidate) string {
+	return cautionMarkdown(conversation.Sources) + interactionMarkdown(result.Warnings) + result.Text +
+		exclusionMarkdown(result.Exclusions) + symptomMarkdown(differential)
+}
+
+// generateResponse asks the model about the medications closest to the
+// question and returns the answer with the drug interactions found among
+// them. The medications are numbered in the prompt and pinned as the
+// sources of the conversation, so the answer can cite them. When no
+// pathology matched, the question is a follow-up and the medications
+// pinned in the conversation are used. The medications that do not suit
+// the patient profile, when given, are excluded. When onChunk is not nil,
+// it receives the answer as it is generated.
+func generateResponse(ctx context.Context, conversation *session.Conversation, match *store.Pathology, queryEmbedding []float64, message string, profile *PatientProfile, onChunk llm.ChunkFunc) (*generatedAnswer, error) {
+	result := &generatedAnswer{}
+
+	// Step 1: Retrieve the medications closest to the question that suit
+	// the patient and pin them
+	if match != nil {
+		medications, excluded, err := findSimilarMedications(ctx, match.ID, searchLimit(), queryEmbedding, profile)
+		if err != nil {
+			return nil, fmt.Errorf("❌ Error retrieving medication embeddings: %w", err)
+		}
+		for _, e := range excluded {
+			configPkg.Log.Infof("🚫 %s excluded for the patient: %s", e.DrugName, e.Reason)
+		}
+		result.Exclusions = excluded
+
+		conversation.Pathology = match.Name
+		conversation.Context = buildPromptForOllama(match.Name, medications) + profilePrompt(profile, excluded)
+		conversation.Sources = citeMedications(medications)
+		if err := sessions.Pin(ctx, conversation.ID, conversation.Pathology, conversation.Context, conversation.Sources); err != nil {
+			return nil, err
+		}
+	}
+
+	// Step 2: Check the medications against each other and against the
+	// ones the user takes, and ask the model to warn about the interactions
+	result.Warnings = checkInteractions(conversation, message, profile)
+	for _, w := range result.Warnings {
+		configPkg.Log.Warnf("⚠️ %s interaction between %s and %s (%s + %s)", w.Severity, w.Products[0], w.Products[1], w.IngredientA, w.IngredientB)
+	}
+
+	// Step 3: Send the conversation to Ollama and get a reply
+	question := session.Message{Role: "user", Content: message, CreatedAt: time.Now()}
+	response, err := sendToOllama(ctx, conversation, message+interactionPrompt(result.Warnings), onChunk)
+	if err != nil {
+		return nil, fmt.Errorf("❌ Error sending request to Ollama: %w", err)
+	}
+
+	// Step 4: Remember the turn for the next questions
+	answer := session.Message{Role: "assistant", Content: response, CreatedAt: time.Now()}
+	if err := sessions.Append(ctx, conversation.ID, question, answer); err != nil {
+		return nil, err
+	}
+
+	// Step 5: Return the content of the answer
+	result.Text = response
+	return result, nil
+}
+
+// Number of candidates searched for each medication returned, so brands of
+// the same ingredients can be folded without returning fewer medications
+const groupingFactor = 4
+
+// findSimilarMedications returns the medications closest to the query embedding.
+// A pathologyID of 0 searches the medications of every pathology. Brands
+// with the same active ingredients as a closer medication are folded into
+// its OtherBrands. With a patient profile, the candidates that do not suit
+// the patient are returned apart with the reason, and the others carry
+// their cautions.
+func findSimilarMedications(ctx context.Context, pathologyID int, limit int, queryEmbedding []float64, profile *PatientProfile) ([]store.Medication, []Exclusion, error) {
+	candidates, err := vectorStore.SearchMedications(ctx, queryEmbedding, pathologyID, limit*groupingFactor)
+	if err != nil {
+		return nil, nil, err
+	}
+	candidates, excluded := applyProfile(profile, candidates)
+	return groupByIngredients(candidates, limit), excluded, nil
+}
+
+// groupByIngredients keeps the closest medication of each set of active
+// ingredients, in rank order. Medications without known ingredients are
+// kept as they are.
+func groupByIngredients(candidates []store.Medication, limit int) []store.Medication {
+	var medications []store.Medication
+	groups := make(map[string]int)
+	for _, med := range candidates {
+		if len(med.Ingredients) > 0 {
+			key := strings.Join(med.Ingredients, "+")
+			if i, ok := groups[key]; ok {
+				if !strings.EqualFold(medications[i].DrugName, med.DrugName) && !slices.ContainsFunc(medications[i].OtherBrands, func(brand string) bool {
+					return strings.EqualFold(brand, med.DrugName)
+				}) {
+					medications[i].OtherBrands = append(medications[i].OtherBrands, med.DrugName)
+				}
+				continue
+			}
+			if len(medications) == limit {
+				continue
+			}
+			groups[key] = len(medications)
+		} else if len(medications) == limit {
+			continue
+		}
+		medications = append(medications, med)
+	}
+	return medications
+}
+
+func buildPromptForOllama(pathology string, medications []store.Medication) string {
+	prompt := fmt.Sprintf("For this pathology: %s, the following medications are available:\n", pathology)
+	for i, med := range medications {
+		prompt += fmt.Sprintf(
+			"[%d] Medication Name: %s\n  Active Ingredients: %s\n  Also Sold As: %s\n  Indications: %s\n  Purpose: %s\n  Dosage: %s\n  Warnings: %s\n  Package Label: %s\n",
+			i+1,
+			med.DrugName,
+			strings.Join(med.Ingredients, ", "),
+			strings.Join(med.OtherBrands, ", "),
+			med.Indications,
+			med.Purpose,
+			med.Dosage,
+			med.Warnings,
+			med.PackageLabel,
+		)
+		if len(med.Cautions) > 0 {
+			prompt += "  Cautions For This Patient: " + strings.Join(med.Cautions, "; ") + "\n"
+		}
+	}
+	prompt += config.Models.Generation.Prompt
+	prompt += citationInstruction
+	//prompt += "Please analyze the medications listed below and recommend at least two for this pathology, displaying dosage and indications."
+	return prompt
+}
+
+// generateEmbedding embeds the user's question with the model of the stored vectors
+func generateEmbedding(ctx context.Context, text string) ([]float64, error) {
+	service, err := currentEmbeddings(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return service.EmbedText(ctx, text)
+}
+
+var embeddingsMu sync.Mutex
+
+// currentEmbeddings returns the embedding service matching the stored
+// vectors. When they are re-embedded while the chatbot runs, the store
+// records another model and the chatbot switches to it.
+func currentEmbeddings(ctx context.Context) (*embedding.Service, error) {
+	model, _, err := vectorStore.EmbeddingModel(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	embeddingsMu.Lock()
+	defer embeddingsMu.Unlock()
+
+	if model == "" || model == embeddings.Model {
+		return embeddings, nil
+	}
+	service, err := embedding.NewModel(ctx, config.Models.Embedding.ProviderConfig, model)
+	if err != nil {
+		return nil, err
+	}
+	if err := service.Check(ctx, vectorStore, false); err != nil {
+		return nil, err
+	}
+	configPkg.Log.Warnf("⚠️ The stored vectors were re-embedded with %s, switching from %s", model, embeddings.Model)
+	embeddings = service
+	return service, nil
+}
+
+const systemPrompt = "You are a licensed and experienced pharmacist with a strong knowledge of drug interactions, indications, and proper dosages. Always respond in English, clearly and concisely."
+
+// tokenBudget returns the configured size of the requests sent to the model
+func tokenBudget() int {
+	if config.Chat.TokenBudget > 0 {
+		return config.Chat.TokenBudget
+	}
+	return defaultTokenBudget
+}
+
+// buildChatMessages pins the retrieved medications in the system message and
+// keeps as many previous turns as the token budget allows
+func buildChatMessages(conversation *session.Conversation, message string) []llm.Message {
+	system := systemPrompt + "\n\n" + conversation.Context
+
+	messages := []llm.Message{{Role: "system", Content: system}}
+	budget := tokenBudget() - session.EstimateTokens(system) - session.EstimateTokens(message)
+	if budget > 0 {
+		for _, m := range session.Trim(conversation.Messages, budget) {
+			messages = append(messages, llm.Message{Role: m.Role, Content: m.Content})
+		}
+	}
+	return append(messages, llm.Message{Role: "user", Content: message})
+}
+
+func sendToOllama(ctx context.Context, conversation *session.Conversation, message string, onChunk llm.ChunkFunc) (string, error) {
+	messages := buildChatMessages(conversation, message)
+
+	var responseContent strings.Builder
+	err := generator.Chat(ctx, config.Models.Generation.Name, messages, func(chunk string) error {
+		responseContent.WriteString(chunk)
+		if onChunk != nil {
+			return onChunk(chunk)
+		}
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return responseContent.String(), nil
+}
+
+// Setup loads the pathologies, connects the model providers and the store,
+// checks that the stored vectors match the embedding model and loads the
+// interaction table, the triage rules and the symptoms of the pathologies
+func Setup(cfg *configPkg.Config) error {
+	var err error
+
+	config = cfg
+	pathology, err = configPkg.LoadPathologies(config.Pathologie.File)
+	if err != nil {
+		return fmt.Errorf("❌ Error loading config pathologies: %w", err)
+	}
+
+	// Initialize the model providers
+	generator, err = llm.New(config.Models.Generation.ProviderConfig)
+	if err != nil {
+		return fmt.Errorf("❌ Error initializing generation provider: %w", err)
+	}
+	embeddings, err = embedding.New(context.Background(), config)
+	if err != nil {
+		return fmt.Errorf("❌ Error initializing embedding model: %w", err)
+	}
+
+	// Initialize the vector store
+	vectorStore, err = store.Open(config)
+	if err != nil {
+		return fmt.Errorf("❌ Error initializing store: %w", err)
+	}
+
+	// Questions must be embedded in the same space as the stored vectors
+	if err := embeddings.Check(context.Background(), vectorStore, false); err != nil {
+		return fmt.Errorf("❌ Embedding model check failed: %w", err)
+	}
+
+	if err := loadInteractions(context.Background()); err != nil {
+		return fmt.Errorf("❌ Error loading the interaction table: %w", err)
+	}
+	if err := loadTriage(context.Background()); err != nil {
+		return fmt.Errorf("❌ Error loading the triage rules: %w", err)
+	}
+	if err := loadSymptoms(context.Background()); err != nil {
+		return fmt.Errorf("❌ Error indexing the symptoms: %w", err)
+	}
+
+	// Conversations are kept in MySQL alongside the vectors when possible
+	if mysqlStore, ok := vectorStore.(*store.MySQLStore); ok {
+		sessions = session.NewMySQL(mysqlStore.DB())
+	} else {
+		sessions = session.NewMemory()
+	}
+	return nil
+}
+
+// Close releases the store opened by Setup
+func Close() error {
+	return vectorStore.Close()
+}
+
+// Retrieve returns the pathology a question is about and the medications
+// that would be given to the model, without generating an answer. The
+// pathology is nil when the question is not understood, or when it needs a
+// clarifying question.
+func Retrieve(ctx context.Context, message string) (*store.Pathology, []store.Medication, error) {
+	queryEmbedding, err := generateEmbedding(ctx, message)
+	if err != nil {
+		return nil, nil, err
+	}
+	inferred, err := inferPathology(ctx, &session.Conversation{}, message, queryEmbedding)
+	if err != nil || inferred.match == nil {
+		return nil, nil, err
+	}
+	match := inferred.match
+	medications, _, err := findSimilarMedications(ctx, match.ID, searchLimit(), queryEmbedding, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	return match, medications, nil
+}
+
+// Ask answers a single question as the chatbot would, in a conversation of
+// its own, for the patient profile when not nil. The response lists the
+// sources, the drug interactions found, the medications excluded for the
+// patient and the differential of the symptoms, or instead of an answer
+// the urgent-care guidance given for red-flag symptoms or a clarifying
+// question. The conversation is ended, except after a clarifying question:
+// only then is the conversation ID returned, so the clarification can be
+// answered through the API. onChunk, when not nil, receives the answer as
+// it is generated.
+func Ask(ctx context.Context, message string, profile *PatientProfile, onChunk llm.ChunkFunc) (*ChatResponse, error) {
+	conversation, err := sessions.Create(ctx)
+	if err != nil {
+		return nil, err
+	}
+	keep := false
+	defer func() {
+		if !keep {
+			sessions.End(context.Background(), conversation.ID)
+		}
+	}()
+
+	queryEmbedding, urgent, err := triageAndEmbed(ctx, conversation, message, profile)
+	if err != nil {
+		return nil, err
+	}
+	if urgent != nil {
+		return &ChatResponse{Markdown: triageMarkdown(urgent), Triage: urgent}, nil
+	}
+	inferred, err := inferPathology(ctx, conversation, message, queryEmbedding)
+	if err != nil {
+		return nil, err
+	}
+	if inferred.clarification != "" {
+		if err := askClarification(ctx, conversation, message, inferred); err != nil {
+			return nil, err
+		}
+		keep = true
+		return &ChatResponse{ConversationID: conversation.ID, Markdown: inferred.clarification, Clarification: inferred.clarification, Differential: nonNil(inferred.differential)}, nil
+	}
+	match := inferred.match
+	if match == nil {
+		return &ChatResponse{Markdown: unsupportedPathologyMessage(), Differential: nonNil(inferred.differential)}, nil
+	}
+
+	result, err := generateResponse(ctx, conversation, match, queryEmbedding, message, profile, onChunk)
+	if err != nil {
+		return nil, err
+	}
+	return &ChatResponse{
+		Answered:     true,
+		Markdown:     answerMarkdown(conversation, result, inferred.differential),
+		Pathology:    &PathologyMatch{ID: match.ID, Name: match.Name, SimilarityScore: match.SimilarityScore},
+		Sources:      answerSources(conversation, result.Text),
+		Interactions: apiWarnings(result.Warnings),
+		Exclusions:   nonNil(result.Exclusions),
+		Differential: nonNil(inferred.differential),
+	}, nil
+}
+
+// contentSecurityPolicy only lets the chat page load its own scripts, styles
+// and fonts: markup injected in an answer cannot run script or load content
+// from another site
+const contentSecurityPolicy = "default-src 'self'; script-src 'self'; style-src 'self'; img-src 'self' data:; " +
+	"font-src 'self'; connect-src 'self'; object-src 'none'; base-uri 'none'; form-action 'self'; frame-ancestors 'none'"
+
+// securityHeaders sets the Content-Security-Policy and related headers on
+// every response
+func securityHeaders(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header := w.Header()
+		header.Set("Content-Security-Policy", contentSecurityPolicy)
+		header.Set("X-Content-Type-Options", "nosniff")
+		header.Set("X-Frame-Options", "DENY")
+		header.Set("Referrer-Policy", "no-referrer")
+		next.ServeHTTP(w, r)
+	})
+}
+
+// Run serves the chatbot on port until the listener fails
+func Run(port int) error {
+	var err error
+	tpl, err = template.ParseFiles("dist/templates/chat.html")
+	if err != nil {
+		return fmt.Errorf("❌ Error loading the chat template: %w", err)
+	}
+
+	fs := http.FileServer(http.Dir("dist"))
+
+	mux := http.NewServeMux()
+	mux.Handle("/dist/", http.StripPrefix("/dist/", fs))
+	mux.HandleFunc("/", indexHandler)
+	mux.HandleFunc("/chat", chatHandler)
+	mux.HandleFunc("/chat/stream", chatStreamHandler)
+	mux.HandleFunc("/chat/clear", clearHandler)
+	mux.Handle(apiPrefix+"/", apiHandler())
+
+	listener, err := net.Listen("tcp", ":"+strconv.Itoa(port))
+	if err != nil {
+		return fmt.Errorf("❌ Error listening on port %d, it may already be in use: %w", port, err)
+	}
+	configPkg.Log.Infof("✅ HTTP service started on port %d\n", port)
+
+	if err := http.Serve(listener, securityHeaders(mux)); err != nil {
+		return fmt.Errorf("❌ Unexpected HTTP service error : %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,218 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	configPkg "github.com/colussim/go-mysql-ai/pkg/config"
+	"github.com/colussim/go-mysql-ai/pkg/session"
+	"github.com/colussim/go-mysql-ai/pkg/store"
+	"github.com/colussim/go-mysql-ai/pkg/symptoms"
+)
+
+// Default similarity between a question and a symptom, default score
+// difference under which two pathologies are too close to choose, and
+// default score a pathology needs to replace the embedding match
+const (
+	defaultSymptomThreshold   = 0.7
+	defaultDifferentialMargin = 0.1
+	defaultSymptomMinimum     = 0.5
+)
+
+// symptomMatcher holds the symptoms of the stored pathologies indexed by
+// Setup, embedded with the model of the questions
+var (
+	symptomMu      sync.Mutex
+	symptomMatcher = symptoms.NewMatcher(nil, defaultSymptomThreshold)
+)
+
+// inference is the pathology a question is about, with the differential of
+// the symptoms it describes. When the differential is too close to choose,
+// match is nil and clarification asks about the symptoms that tell the
+// candidates apart.
+type inference struct {
+	match         *store.Pathology
+	differential  []symptoms.Candidate
+	clarification string
+}
+
+// symptomThreshold returns the configured similarity of a symptom match
+func symptomThreshold() float64 {
+	if config.Symptoms.Threshold > 0 {
+		return config.Symptoms.Threshold
+	}
+	return defaultSymptomThreshold
+}
+
+// differentialMargin returns the configured margin of the differential
+func differentialMargin() float64 {
+	if config.Symptoms.Margin > 0 {
+		return config.Symptoms.Margin
+	}
+	return defaultDifferentialMargin
+}
+
+// symptomMinimum returns the configured score a pathology needs to replace
+// the embedding match of a question
+func symptomMinimum() float64 {
+	if config.Symptoms.Minimum > 0 {
+		return config.Symptoms.Minimum
+	}
+	return defaultSymptomMinimum
+}
+
+// loadSymptoms indexes the symptoms of the stored pathologies, as listed in
+// the pathology file, and embeds them
+func loadSymptoms(ctx context.Context) error {
+	stored, err := vectorStore.ListPathologies(ctx)
+	if err != nil {
+		return err
+	}
+	list := make(map[string][]string)
+	for _, p := range stored {
+		list[p.Name] = pathology.Pathologies[p.Name].Symptoms
+	}
+	matcher, err := symptoms.NewMatcher(list, symptomThreshold()).WithEmbeddings(ctx, embeddings.Model, embeddings.Embed)
+	if err != nil {
+		return err
+	}
+
+	symptomMu.Lock()
+	symptomMatcher = matcher
+	symptomMu.Unlock()
+	return nil
+}
+
+// currentSymptoms returns the symptom matcher with the symptoms embedded
+// like the questions: when the chatbot switches to another embedding model,
+// the symptoms are embedded again
+func currentSymptoms(ctx context.Context) (*symptoms.Matcher, error) {
+	service, err := currentEmbeddings(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	symptomMu.Lock()
+	defer symptomMu.Unlock()
+	if symptomMatcher.Len() == 0 || symptomMatcher.Model() == service.Model {
+		return symptomMatcher, nil
+	}
+	matcher, err := symptomMatcher.WithEmbeddings(ctx, service.Model, service.Embed)
+	if err != nil {
+		return nil, err
+	}
+	symptomMatcher = matcher
+	return matcher, nil
+}
+
+// inferPathology matches a question with a pathology from the symptoms it
+// describes, then by embedding like matchPathology. The pathology the
+// question names, or the one whose symptoms it describes best when they
+// score at least the minimum and better than the embedding match, is chosen;
+// when several are too close, the embedding match settles between them, or
+// a clarifying question is asked. Until a pathology is pinned, the earlier
+// questions of the conversation are read with this one, so the answer to a
+// clarifying question completes the symptoms first described.
+func inferPathology(ctx context.Context, conversation *session.Conversation, message string, queryEmbedding []float64) (*inference, error) {
+	text, textEmbedding := message, queryEmbedding
+	if conversation.Context == "" {
+		var earlier []string
+		for _, m := range conversation.Messages {
+			if m.Role == "user" {
+				earlier = append(earlier, m.Content)
+			}
+		}
+		if len(earlier) > 0 {
+			text = strings.Join(append(earlier, message), "\n")
+			var err error
+			if textEmbedding, err = generateEmbedding(ctx, text); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	matcher, err := currentSymptoms(ctx)
+	if err != nil {
+		return nil, err
+	}
+	match, err := matchPathology(ctx, queryEmbedding)
+	if err != nil {
+		return nil, err
+	}
+	result := &inference{match: match, differential: matcher.Rank(text, textEmbedding)}
+	if len(result.differential) == 0 {
+		return result, nil
+	}
+
+	if tied := symptoms.Close(result.differential, differentialMargin()); tied != nil {
+		if match != nil && slices.ContainsFunc(tied, func(c symptoms.Candidate) bool { return c.Pathology == match.Name }) {
+			return result, nil
+		}
+		result.match = nil
+		result.clarification = symptoms.ClarifyingQuestion(tied)
+		return result, nil
+	}
+
+	// The pathology the question names replaces the embedding match, the
+	// one whose symptoms it describes only with enough of them and a
+	// better score
+	leader := result.differential[0]
+	if match != nil && match.Name == leader.Pathology {
+		return result, nil
+	}
+	score := leader.Score
+	if leader.Named {
+		score = 1
+	} else if score < symptomMinimum() || (match != nil && score <= match.SimilarityScore) {
+		return result, nil
+	}
+	stored, err := vectorStore.ListPathologies(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range stored {
+		if p.Name == leader.Pathology {
+			result.match = &store.Pathology{ID: p.ID, Name: p.Name, SimilarityScore: score}
+			break
+		}
+	}
+	return result, nil
+}
+
+// askClarification records a clarifying question in the conversation, so
+// the next question is read with this one
+func askClarification(ctx context.Context, conversation *session.Conversation, message string, inferred *inference) error {
+	names := make([]string, 0, len(inferred.differential))
+	for _, c := range inferred.differential {
+		names = append(names, fmt.Sprintf("%s (%.2f)", c.Pathology, c.Score))
+	}
+	configPkg.Log.Infof("❔ Close differential in conversation %s: %s", conversation.ID, strings.Join(names, ", "))
+
+	question := session.Message{Role: "user", Content: message, CreatedAt: time.Now()}
+	answer := session.Message{Role: "assistant", Content: inferred.clarification, CreatedAt: time.Now()}
+	return sessions.Append(ctx, conversation.ID, question, answer)
+}
+
+// symptomMarkdown lists the symptoms matched for each pathology of a
+// differential under an answer
+func symptomMarkdown(differential []symptoms.Candidate) string {
+	var text strings.Builder
+	for _, c := range differential {
+		if len(c.Matched) == 0 {
+			continue
+		}
+		var matched []string
+		for _, s := range c.Matched {
+			matched = append(matched, s.Symptom)
+		}
+		fmt.Fprintf(&text, "- %s (%.2f): %s\n", c.Pathology, c.Score, strings.Join(matched, ", "))
+	}
+	if text.Len() == 0 {
+		return ""
+	}
+	return "\n\n**🔎 Matched symptoms**\n\n" + text.String()
+}
@@ -0,0 +1,71 @@
+package server
+
+import (
+	"context"
+	"testing"
+
+	configPkg "github.com/colussim/go-mysql-ai/pkg/config"
+	"github.com/colussim/go-mysql-ai/pkg/embedding"
+	"github.com/colussim/go-mysql-ai/pkg/session"
+	"github.com/colussim/go-mysql-ai/pkg/store"
+	"github.com/colussim/go-mysql-ai/pkg/symptoms"
+)
+
+func TestInferPathology(t *testing.T) {
+	ctx := context.Background()
+	memory := store.NewMemory()
+	for _, p := range []store.Pathology{
+		{Name: "cold", Embedding: []float64{1, 0}},
+		{Name: "fever", Embedding: []float64{0, 1}},
+	} {
+		if err := memory.UpsertPathology(ctx, &p); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	savedStore, savedConfig, savedEmbeddings, savedMatcher := vectorStore, config, embeddings, symptomMatcher
+	vectorStore = memory
+	config = &configPkg.Config{}
+	config.Search.Threshold = 0.5
+	embeddings = &embedding.Service{}
+	symptomMatcher = symptoms.NewMatcher(map[string][]string{
+		"cold":  {"runny nose", "sneezing", "sore throat", "cough"},
+		"fever": {"high temperature", "chills", "sweating", "headache"},
+	}, defaultSymptomThreshold)
+	t.Cleanup(func() {
+		vectorStore, config, embeddings, symptomMatcher = savedStore, savedConfig, savedEmbeddings, savedMatcher
+	})
+
+	// Close to cold, far from both, and between them at 0.9 from cold
+	cold, neither, nearCold := []float64{1, 0}, []float64{-1, 0}, []float64{0.9, 0.4359}
+
+	tests := []struct {
+		name      string
+		question  string
+		embedding []float64
+		want      string
+	}{
+		{"embedding match without symptoms", "What can I take?", cold, "cold"},
+		{"one symptom under the minimum", "I have chills", cold, "cold"},
+		{"one symptom without embedding match", "I have chills", neither, ""},
+		{"half the symptoms without embedding match", "I have chills and I am sweating", neither, "fever"},
+		{"half the symptoms under the match similarity", "I have chills and I am sweating", nearCold, "cold"},
+		{"all the symptoms over the match similarity", "Chills, sweating, a headache and a high temperature", nearCold, "fever"},
+		{"named pathology", "Is it a fever?", cold, "fever"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			inferred, err := inferPathology(ctx, &session.Conversation{}, tt.question, tt.embedding)
+			if err != nil {
+				t.Fatalf("inferPathology: %v", err)
+			}
+			var got string
+			if inferred.match != nil {
+				got = inferred.match.Name
+			}
+			if got != tt.want {
+				t.Errorf("inferPathology(%q) = %q, want %q", tt.question, got, tt.want)
+			}
+		})
+	}
+}
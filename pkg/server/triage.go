@@ -0,0 +1,131 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	configPkg "github.com/colussim/go-mysql-ai/pkg/config"
+	"github.com/colussim/go-mysql-ai/pkg/session"
+	"github.com/colussim/go-mysql-ai/pkg/triage"
+)
+
+// triageChecker holds the red-flag rules loaded by Setup, with their
+// descriptions embedded with the model of the questions
+var (
+	triageMu      sync.Mutex
+	triageChecker = triage.NewChecker(nil)
+)
+
+// Triage is the urgent-care guidance given instead of an answer when a
+// question has red-flag symptoms
+type Triage struct {
+	Rule    string   `json:"rule" description:"ID of the red-flag rule"`
+	Urgency string   `json:"urgency" description:"urgent (a doctor or urgent care today) or emergency (the emergency services now)"`
+	Title   string   `json:"title"`
+	Advice  string   `json:"advice"`
+	Matched []string `json:"matched" description:"Terms found in the question, or the red-flag description it is close to"`
+}
+
+// loadTriage reads the red-flag rules of the configured triage file and
+// embeds their descriptions
+func loadTriage(ctx context.Context) error {
+	if config.Triage.File == "" {
+		configPkg.Log.Warn("⚠️ No triage file configured: questions are not checked for red-flag symptoms")
+		return nil
+	}
+	set, err := triage.Load(config.Triage.File)
+	if err != nil {
+		return err
+	}
+	checker, err := triage.NewChecker(set).WithEmbeddings(ctx, embeddings.Model, embeddings.Embed)
+	if err != nil {
+		return err
+	}
+
+	triageMu.Lock()
+	triageChecker = checker
+	triageMu.Unlock()
+	configPkg.Log.Infof("✅ %d triage rules loaded from %s", checker.Len(), config.Triage.File)
+	return nil
+}
+
+// currentTriage returns the triage rules with their descriptions embedded
+// like the questions: when the chatbot switches to another embedding model,
+// the descriptions are embedded again
+func currentTriage(ctx context.Context) (*triage.Checker, error) {
+	service, err := currentEmbeddings(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	triageMu.Lock()
+	defer triageMu.Unlock()
+	if triageChecker.Len() == 0 || triageChecker.Model() == service.Model {
+		return triageChecker, nil
+	}
+	checker, err := triageChecker.WithEmbeddings(ctx, service.Model, service.Embed)
+	if err != nil {
+		return nil, err
+	}
+	triageChecker = checker
+	return checker, nil
+}
+
+// triageAndEmbed checks a question for red-flag symptoms and embeds it. A
+// question matching a rule by its terms is not embedded; otherwise its
+// embedding is compared with the descriptions of the rules. On a match, the
+// urgent-care guidance is logged and recorded in the conversation, and no
+// medication must be retrieved.
+func triageAndEmbed(ctx context.Context, conversation *session.Conversation, message string, profile *PatientProfile) ([]float64, *Triage, error) {
+	var ageBand string
+	if profile != nil {
+		ageBand = profile.AgeBand
+	}
+
+	triageMu.Lock()
+	checker := triageChecker
+	triageMu.Unlock()
+	match := checker.Check(message, ageBand)
+
+	var queryEmbedding []float64
+	if match == nil {
+		var err error
+		queryEmbedding, err = generateEmbedding(ctx, message)
+		if err != nil {
+			return nil, nil, err
+		}
+		if checker, err = currentTriage(ctx); err != nil {
+			return nil, nil, err
+		}
+		match = checker.CheckSimilar(message, ageBand, queryEmbedding)
+	}
+	if match == nil {
+		return queryEmbedding, nil, nil
+	}
+
+	result := &Triage{Rule: match.ID, Urgency: match.Urgency, Title: match.Title, Advice: match.Advice, Matched: match.Matched}
+	if match.Similarity > 0 {
+		configPkg.Log.Warnf("🚑 Triage %s (%s) in conversation %s: similar to %q (%.3f)", result.Rule, result.Urgency, conversation.ID, result.Matched[0], match.Similarity)
+	} else {
+		configPkg.Log.Warnf("🚑 Triage %s (%s) in conversation %s: %s", result.Rule, result.Urgency, conversation.ID, strings.Join(result.Matched, ", "))
+	}
+
+	question := session.Message{Role: "user", Content: message, CreatedAt: time.Now()}
+	answer := session.Message{Role: "assistant", Content: triageMarkdown(result), CreatedAt: time.Now()}
+	if err := sessions.Append(ctx, conversation.ID, question, answer); err != nil {
+		return nil, nil, err
+	}
+	return queryEmbedding, result, nil
+}
+
+// triageMarkdown writes the urgent-care guidance of a triage
+func triageMarkdown(t *Triage) string {
+	heading := "⚠️ Urgent"
+	if t.Urgency == "emergency" {
+		heading = "🚑 Emergency"
+	}
+	return fmt.Sprintf("**%s: %s**\n\n%s\n\nThese symptoms need a medical professional: no medication is recommended for them here.\n", heading, t.Title, t.Advice)
+}
@@ -0,0 +1,78 @@
+package session
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// MemoryStore keeps conversations in process memory, for stores other than MySQL
+type MemoryStore struct {
+	mu            sync.Mutex
+	conversations map[string]*Conversation
+}
+
+func NewMemory() *MemoryStore {
+	return &MemoryStore{conversations: make(map[string]*Conversation)}
+}
+
+func (s *MemoryStore) Create(ctx context.Context) (*Conversation, error) {
+	id, err := newID()
+	if err != nil {
+		return nil, fmt.Errorf("❌ Error generating session ID: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.conversations[id] = &Conversation{ID: id}
+	return &Conversation{ID: id}, nil
+}
+
+func (s *MemoryStore) Get(ctx context.Context, id string) (*Conversation, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c, ok := s.conversations[id]
+	if !ok {
+		return nil, nil
+	}
+	conversation := *c
+	conversation.Sources = append([]Source(nil), c.Sources...)
+	conversation.Messages = append([]Message(nil), c.Messages...)
+	return &conversation, nil
+}
+
+func (s *MemoryStore) Pin(ctx context.Context, id string, pathology string, context string, sources []Source) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c, ok := s.conversations[id]
+	if !ok {
+		return fmt.Errorf("❌ Unknown conversation: %s", id)
+	}
+	c.Pathology = pathology
+	c.Context = context
+	c.Sources = append([]Source(nil), sources...)
+	return nil
+}
+
+func (s *MemoryStore) Append(ctx context.Context, id string, messages ...Message) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c, ok := s.conversations[id]
+	if !ok {
+		return fmt.Errorf("❌ Unknown conversation: %s", id)
+	}
+	c.Messages = append(c.Messages, messages...)
+	return nil
+}
+
+func (s *MemoryStore) End(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.conversations, id)
+	return nil
+}
@@ -0,0 +1,116 @@
+package session
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// MySQLStore keeps conversations in the conversations and messages tables
+type MySQLStore struct {
+	db *sql.DB
+}
+
+func NewMySQL(db *sql.DB) *MySQLStore {
+	return &MySQLStore{db: db}
+}
+
+func (s *MySQLStore) Create(ctx context.Context) (*Conversation, error) {
+	id, err := newID()
+	if err != nil {
+		return nil, fmt.Errorf("❌ Error generating session ID: %w", err)
+	}
+
+	if _, err := s.db.ExecContext(ctx, "INSERT INTO conversations (id) VALUES (?)", id); err != nil {
+		return nil, fmt.Errorf("❌ Error inserting into conversations table: %w", err)
+	}
+	return &Conversation{ID: id}, nil
+}
+
+func (s *MySQLStore) Get(ctx context.Context, id string) (*Conversation, error) {
+	c := Conversation{ID: id}
+	var sources []byte
+	err := s.db.QueryRowContext(ctx, `
+	SELECT COALESCE(pathology, ''), COALESCE(context, ''), sources
+	FROM conversations
+	WHERE id = ? AND ended_at IS NULL`, id).Scan(&c.Pathology, &c.Context, &sources)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("❌ Error retrieving conversation: %w", err)
+	}
+	if len(sources) > 0 {
+		if err := json.Unmarshal(sources, &c.Sources); err != nil {
+			return nil, fmt.Errorf("❌ Error decoding conversation sources: %w", err)
+		}
+	}
+
+	rows, err := s.db.QueryContext(ctx, `
+	SELECT role, content, created_at
+	FROM messages
+	WHERE conversation_id = ?
+	ORDER BY id`, id)
+	if err != nil {
+		return nil, fmt.Errorf("❌ Error querying messages: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var m Message
+		if err := rows.Scan(&m.Role, &m.Content, &m.CreatedAt); err != nil {
+			return nil, fmt.Errorf("❌ Error scanning row: %w", err)
+		}
+		c.Messages = append(c.Messages, m)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("❌ Error iterating over rows: %w", err)
+	}
+
+	return &c, nil
+}
+
+func (s *MySQLStore) Pin(ctx context.Context, id string, pathology string, context string, sources []Source) error {
+	encoded, err := json.Marshal(sources)
+	if err != nil {
+		return fmt.Errorf("❌ Error encoding conversation sources: %w", err)
+	}
+
+	_, err = s.db.ExecContext(ctx, "UPDATE conversations SET pathology = ?, context = ?, sources = ? WHERE id = ?", pathology, context, string(encoded), id)
+	if err != nil {
+		return fmt.Errorf("❌ Error updating conversation: %w", err)
+	}
+	return nil
+}
+
+func (s *MySQLStore) Append(ctx context.Context, id string, messages ...Message) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("❌ Error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	for _, m := range messages {
+		_, err := tx.ExecContext(ctx, "INSERT INTO messages (conversation_id, role, content, created_at) VALUES (?, ?, ?, ?)",
+			id, m.Role, m.Content, m.CreatedAt)
+		if err != nil {
+			return fmt.Errorf("❌ Error inserting into messages table: %w", err)
+		}
+	}
+	if _, err := tx.ExecContext(ctx, "UPDATE conversations SET updated_at = CURRENT_TIMESTAMP WHERE id = ?", id); err != nil {
+		return fmt.Errorf("❌ Error updating conversation: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+func (s *MySQLStore) End(ctx context.Context, id string) error {
+	_, err := s.db.ExecContext(ctx, "UPDATE conversations SET ended_at = CURRENT_TIMESTAMP WHERE id = ? AND ended_at IS NULL", id)
+	if err != nil {
+		return fmt.Errorf("❌ Error ending conversation: %w", err)
+	}
+	return nil
+}
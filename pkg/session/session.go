@@ -0,0 +1,82 @@
+package session
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"time"
+)
+
+type Message struct {
+	Role      string    `json:"role"`
+	Content   string    `json:"content"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Source is a medication label given to the model, cited in the answers
+// with its marker: [1], [2]...
+type Source struct {
+	Marker          int      `json:"marker"`
+	MedicationID    int      `json:"medication_id"`
+	DrugName        string   `json:"drug_name"`
+	SetID           string   `json:"set_id,omitempty"`
+	Sections        []string `json:"sections"`
+	SimilarityScore float64  `json:"similarity_score"`
+	// Active ingredients, checked for interactions
+	Ingredients []string `json:"ingredients,omitempty"`
+	// Brands of the same active ingredients folded into this source
+	OtherBrands []string `json:"other_brands,omitempty"`
+	// Cautions for the patient of the conversation
+	Cautions []string `json:"cautions,omitempty"`
+}
+
+// Conversation is one chat session. Context holds the medications retrieved
+// for the current pathology: it is pinned in every request sent to the model
+// so follow-up questions keep the same grounding. Sources are the
+// medications of Context that the answers can cite.
+type Conversation struct {
+	ID        string    `json:"id"`
+	Pathology string    `json:"pathology"`
+	Context   string    `json:"context"`
+	Sources   []Source  `json:"sources,omitempty"`
+	Messages  []Message `json:"messages"`
+}
+
+// Store persists conversations. Get returns nil for unknown or ended
+// conversations.
+type Store interface {
+	Create(ctx context.Context) (*Conversation, error)
+	Get(ctx context.Context, id string) (*Conversation, error)
+	Pin(ctx context.Context, id string, pathology string, context string, sources []Source) error
+	Append(ctx context.Context, id string, messages ...Message) error
+	End(ctx context.Context, id string) error
+}
+
+// newID returns a random conversation ID, used as the session cookie value
+func newID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// EstimateTokens approximates the number of tokens of a text (about four
+// characters per token for English)
+func EstimateTokens(text string) int {
+	return (len(text) + 3) / 4
+}
+
+// Trim keeps the most recent messages that fit in the token budget
+func Trim(messages []Message, budget int) []Message {
+	start := len(messages)
+	for start > 0 {
+		cost := EstimateTokens(messages[start-1].Content)
+		if cost > budget {
+			break
+		}
+		budget -= cost
+		start--
+	}
+	return messages[start:]
+}
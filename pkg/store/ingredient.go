@@ -0,0 +1,92 @@
+package store
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+)
+
+var (
+	// "Active ingredient (in each tablet)" heading of the label section
+	ingredientHeading = regexp.MustCompile(`^active ingredients?\s*(\([^)]*\))?\s*:?\s*`)
+	// Parenthesized notes such as "(nsaid)*"
+	ingredientNote = regexp.MustCompile(`\([^)]*\)\**`)
+	// Strengths: "200 mg", "0.5%", "10 mg/5 ml"
+	ingredientStrength    = regexp.MustCompile(`\b\d+([.,]\d+)?\s*(mg|mcg|g|ml|iu|units?)?\b`)
+	ingredientPunctuation = regexp.MustCompile(`[^a-z0-9\s-]+`)
+)
+
+// Words of a salt form or a grade that follow the name of an active moiety:
+// "naproxen sodium" and "diphenhydramine hcl" are naproxen and
+// diphenhydramine. They are only removed at the end of a name that keeps at
+// least one word, so "calcium carbonate" is unchanged.
+var ingredientSaltWords = map[string]bool{
+	"sodium": true, "potassium": true, "calcium": true, "magnesium": true,
+	"hydrochloride": true, "dihydrochloride": true, "hcl": true,
+	"hydrobromide": true, "hbr": true, "bromide": true,
+	"maleate": true, "citrate": true, "sulfate": true, "succinate": true,
+	"tartrate": true, "bitartrate": true, "mesylate": true, "besylate": true,
+	"phosphate": true, "acetate": true, "fumarate": true,
+	"monohydrate": true, "dihydrate": true, "anhydrous": true,
+	"usp": true, "nf": true,
+}
+
+// CanonicalIngredient returns the name an active ingredient is stored
+// under: lowercased, without strength, notes and salt form. It returns an
+// empty name when nothing is left.
+func CanonicalIngredient(name string) string {
+	name = strings.ToLower(strings.TrimSpace(name))
+	name = ingredientHeading.ReplaceAllString(name, "")
+	name = ingredientNote.ReplaceAllString(name, " ")
+	name = ingredientStrength.ReplaceAllString(name, " ")
+	name = ingredientPunctuation.ReplaceAllString(name, " ")
+
+	words := strings.Fields(name)
+	for len(words) > 1 && ingredientSaltWords[words[len(words)-1]] {
+		words = words[:len(words)-1]
+	}
+	return strings.Trim(strings.Join(words, " "), "-")
+}
+
+// CanonicalIngredients canonicalizes the active ingredients of a label,
+// given one per entry or separated with commas or semicolons. The result
+// is sorted, without duplicates, and never nil.
+func CanonicalIngredients(names []string) []string {
+	ingredients := []string{}
+	for _, entry := range names {
+		for _, name := range strings.FieldsFunc(entry, func(r rune) bool { return r == ',' || r == ';' }) {
+			if canonical := CanonicalIngredient(name); canonical != "" && !slices.Contains(ingredients, canonical) {
+				ingredients = append(ingredients, canonical)
+			}
+		}
+	}
+	sort.Strings(ingredients)
+	return ingredients
+}
+
+// ingredientsColumn scans the comma separated ingredient names returned by
+// GROUP_CONCAT into Medication.Ingredients
+type ingredientsColumn []string
+
+func (c *ingredientsColumn) Scan(src any) error {
+	var list string
+	switch v := src.(type) {
+	case nil:
+	case string:
+		list = v
+	case []byte:
+		list = string(v)
+	default:
+		return fmt.Errorf("❌ Unexpected ingredient list type %T", src)
+	}
+
+	ingredients := []string{}
+	if list != "" {
+		ingredients = strings.Split(list, ",")
+	}
+	sort.Strings(ingredients)
+	*c = ingredients
+	return nil
+}
@@ -0,0 +1,61 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Interaction is a known interaction between two active ingredients. The
+// ingredients are stored canonicalized and in alphabetical order, so a pair
+// is stored once whatever the order and form it was given in.
+type Interaction struct {
+	ID          int    `json:"id"`
+	IngredientA string `json:"ingredient_a"`
+	IngredientB string `json:"ingredient_b"`
+	Severity    string `json:"severity" description:"minor, moderate, major or contraindicated"`
+	Description string `json:"description"`
+	Source      string `json:"source,omitempty" description:"Reference the interaction comes from"`
+}
+
+// Severities of the interactions, from the least to the most severe
+var Severities = []string{"minor", "moderate", "major", "contraindicated"}
+
+// InteractionStore is implemented by stores that keep the drug interaction
+// table. Interactions are keyed on their pair of ingredients: upserting a
+// known pair updates it. ListInteractions returns them ordered by pair.
+type InteractionStore interface {
+	UpsertInteractions(ctx context.Context, interactions []*Interaction) error
+	ListInteractions(ctx context.Context) ([]Interaction, error)
+}
+
+// NormalizeInteraction canonicalizes and orders the ingredients of an
+// interaction and checks its severity
+func NormalizeInteraction(i *Interaction) error {
+	a := CanonicalIngredient(i.IngredientA)
+	b := CanonicalIngredient(i.IngredientB)
+	if a == "" || b == "" {
+		return fmt.Errorf("❌ An interaction needs two ingredients")
+	}
+	if a == b {
+		return fmt.Errorf("❌ The interaction of %s is with itself", a)
+	}
+	if b < a {
+		a, b = b, a
+	}
+	i.IngredientA, i.IngredientB = a, b
+
+	i.Severity = strings.ToLower(strings.TrimSpace(i.Severity))
+	if SeverityRank(i.Severity) < 0 {
+		return fmt.Errorf("❌ Unknown severity %q for %s and %s, expected one of %s", i.Severity, a, b, strings.Join(Severities, ", "))
+	}
+	i.Description = strings.TrimSpace(i.Description)
+	i.Source = strings.TrimSpace(i.Source)
+	return nil
+}
+
+// SeverityRank returns the index of a severity in Severities, or -1
+func SeverityRank(severity string) int {
+	return slices.Index(Severities, severity)
+}
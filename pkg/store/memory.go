@@ -0,0 +1,407 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+)
+
+// MemoryStore keeps everything in process memory. When a path is given the
+// content is loaded from that JSON snapshot on open and written back on
+// Close, so an import can be served by a later process.
+type MemoryStore struct {
+	mu          sync.RWMutex
+	path        string
+	nextID      int
+	pathologies map[int]Pathology
+	medications map[int]Medication
+	// Interactions keyed on their pair of ingredients
+	interactions map[[2]string]Interaction
+	model        string
+	dimension    int
+}
+
+type memorySnapshot struct {
+	EmbeddingModel     string        `json:"embedding_model,omitempty"`
+	EmbeddingDimension int           `json:"embedding_dimension,omitempty"`
+	Pathologies        []Pathology   `json:"pathologies"`
+	Medications        []Medication  `json:"medications"`
+	Interactions       []Interaction `json:"interactions,omitempty"`
+}
+
+func OpenMemory(path string) (*MemoryStore, error) {
+	s := NewMemory()
+	s.path = path
+	if path == "" {
+		return s, nil
+	}
+
+	file, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("❌ Error reading memory store snapshot: %w", err)
+	}
+
+	var snapshot memorySnapshot
+	if err := json.Unmarshal(file, &snapshot); err != nil {
+		return nil, fmt.Errorf("❌ Error parsing memory store snapshot: %w", err)
+	}
+	s.model = snapshot.EmbeddingModel
+	s.dimension = snapshot.EmbeddingDimension
+	for _, p := range snapshot.Pathologies {
+		s.pathologies[p.ID] = p
+		s.nextID = max(s.nextID, p.ID)
+	}
+	for _, m := range snapshot.Medications {
+		s.medications[m.ID] = m
+		s.nextID = max(s.nextID, m.ID)
+	}
+	for _, i := range snapshot.Interactions {
+		s.interactions[[2]string{i.IngredientA, i.IngredientB}] = i
+		s.nextID = max(s.nextID, i.ID)
+	}
+
+	return s, nil
+}
+
+func NewMemory() *MemoryStore {
+	return &MemoryStore{
+		pathologies:  make(map[int]Pathology),
+		medications:  make(map[int]Medication),
+		interactions: make(map[[2]string]Interaction),
+	}
+}
+
+func (s *MemoryStore) Close() error {
+	if s.path == "" {
+		return nil
+	}
+
+	s.mu.RLock()
+	snapshot := memorySnapshot{
+		EmbeddingModel:     s.model,
+		EmbeddingDimension: s.dimension,
+		Pathologies:        sortedValues(s.pathologies),
+		Medications:        sortedValues(s.medications),
+		Interactions:       s.sortedInteractions(),
+	}
+	s.mu.RUnlock()
+
+	file, err := json.Marshal(snapshot)
+	if err != nil {
+		return fmt.Errorf("❌ Error encoding memory store snapshot: %w", err)
+	}
+	if err := os.WriteFile(s.path, file, 0o644); err != nil {
+		return fmt.Errorf("❌ Error writing memory store snapshot: %w", err)
+	}
+	return nil
+}
+
+func (s *MemoryStore) UpsertPathology(ctx context.Context, p *Pathology) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	p.ID = 0
+	for id, existing := range s.pathologies {
+		if existing.Name == p.Name {
+			p.ID = id
+			break
+		}
+	}
+	if p.ID == 0 {
+		s.nextID++
+		p.ID = s.nextID
+	}
+
+	stored := *p
+	stored.SimilarityScore = 0
+	s.pathologies[p.ID] = stored
+	return nil
+}
+
+func (s *MemoryStore) UpsertMedication(ctx context.Context, m *Medication) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	pathology, ok := s.pathologies[m.PathologyID]
+	if !ok {
+		return fmt.Errorf("❌ Unknown pathology ID: %d", m.PathologyID)
+	}
+	if m.ID == 0 && m.SetID != "" {
+		for id, existing := range s.medications {
+			if existing.PathologyID == m.PathologyID && existing.SetID == m.SetID {
+				m.ID = id
+				break
+			}
+		}
+	}
+
+	stored := *m
+	if m.ID == 0 {
+		if m.Embedding == nil {
+			return fmt.Errorf("❌ Missing embedding for new medication: %s", m.DrugName)
+		}
+		s.nextID++
+		m.ID = s.nextID
+	} else {
+		existing, ok := s.medications[m.ID]
+		if !ok {
+			return fmt.Errorf("❌ Unknown medication ID: %d", m.ID)
+		}
+		if stored.Embedding == nil {
+			stored.Embedding = existing.Embedding
+		}
+		if stored.Ingredients == nil {
+			stored.Ingredients = existing.Ingredients
+		}
+	}
+	m.Pathology = pathology.Name
+
+	stored.ID = m.ID
+	stored.Pathology = pathology.Name
+	stored.SimilarityScore = 0
+	stored.OtherBrands = nil
+	stored.Cautions = nil
+	s.medications[m.ID] = stored
+	return nil
+}
+
+// UpsertMedications writes the medications one by one: the memory store
+// has no transactions
+func (s *MemoryStore) UpsertMedications(ctx context.Context, medications []*Medication) error {
+	for _, m := range medications {
+		if err := s.UpsertMedication(ctx, m); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *MemoryStore) SearchPathologies(ctx context.Context, vector []float64, limit int) ([]Pathology, error) {
+	s.mu.RLock()
+	pathologies := sortedValues(s.pathologies)
+	s.mu.RUnlock()
+
+	return rankPathologies(pathologies, vector, limit), nil
+}
+
+func (s *MemoryStore) SearchMedications(ctx context.Context, vector []float64, pathologyID int, limit int) ([]Medication, error) {
+	s.mu.RLock()
+	medications := s.filterMedications(pathologyID)
+	s.mu.RUnlock()
+
+	return rankMedications(medications, vector, limit), nil
+}
+
+func (s *MemoryStore) ListPathologies(ctx context.Context) ([]Pathology, error) {
+	s.mu.RLock()
+	pathologies := sortedValues(s.pathologies)
+	s.mu.RUnlock()
+
+	for i := range pathologies {
+		pathologies[i].Embedding = nil
+	}
+	sort.Slice(pathologies, func(i, j int) bool {
+		return pathologies[i].Name < pathologies[j].Name
+	})
+	return pathologies, nil
+}
+
+func (s *MemoryStore) ListMedications(ctx context.Context, pathologyID int) ([]Medication, error) {
+	s.mu.RLock()
+	medications := s.filterMedications(pathologyID)
+	s.mu.RUnlock()
+
+	for i := range medications {
+		medications[i].Embedding = nil
+	}
+	return medications, nil
+}
+
+func (s *MemoryStore) GetMedication(ctx context.Context, id int) (*Medication, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	med, ok := s.medications[id]
+	if !ok {
+		return nil, nil
+	}
+	med.Embedding = nil
+	return &med, nil
+}
+
+func (s *MemoryStore) DeletePathology(ctx context.Context, id int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for medID, m := range s.medications {
+		if m.PathologyID == id {
+			delete(s.medications, medID)
+		}
+	}
+	delete(s.pathologies, id)
+	return nil
+}
+
+func (s *MemoryStore) DeleteMedication(ctx context.Context, id int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.medications, id)
+	return nil
+}
+
+func (s *MemoryStore) UpsertInteractions(ctx context.Context, interactions []*Interaction) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, i := range interactions {
+		if err := NormalizeInteraction(i); err != nil {
+			return err
+		}
+		key := [2]string{i.IngredientA, i.IngredientB}
+		if existing, ok := s.interactions[key]; ok {
+			i.ID = existing.ID
+		} else {
+			s.nextID++
+			i.ID = s.nextID
+		}
+		s.interactions[key] = *i
+	}
+	return nil
+}
+
+func (s *MemoryStore) ListInteractions(ctx context.Context) ([]Interaction, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.sortedInteractions(), nil
+}
+
+// sortedInteractions returns the interactions ordered by pair. The caller
+// must hold the lock.
+func (s *MemoryStore) sortedInteractions() []Interaction {
+	interactions := make([]Interaction, 0, len(s.interactions))
+	for _, i := range s.interactions {
+		interactions = append(interactions, i)
+	}
+	sort.Slice(interactions, func(i, j int) bool {
+		if interactions[i].IngredientA != interactions[j].IngredientA {
+			return interactions[i].IngredientA < interactions[j].IngredientA
+		}
+		return interactions[i].IngredientB < interactions[j].IngredientB
+	})
+	return interactions
+}
+
+func (s *MemoryStore) EmbeddingModel(ctx context.Context) (string, int, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.model, s.dimension, nil
+}
+
+func (s *MemoryStore) SetEmbeddingModel(ctx context.Context, model string, dimension int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.model = model
+	s.dimension = dimension
+	return nil
+}
+
+// memoryReembedding keeps the new vectors aside until Commit
+type memoryReembedding struct {
+	store       *MemoryStore
+	model       string
+	dimension   int
+	pathologies map[int]Pathology
+	medications map[int]Medication
+}
+
+func (s *MemoryStore) BeginReembed(ctx context.Context, model string, dimension int) (Reembedding, error) {
+	return &memoryReembedding{
+		store:       s,
+		model:       model,
+		dimension:   dimension,
+		pathologies: make(map[int]Pathology),
+		medications: make(map[int]Medication),
+	}, nil
+}
+
+func (r *memoryReembedding) SetPathologyVector(ctx context.Context, id int, contentHash string, vector []float64) error {
+	r.pathologies[id] = Pathology{ContentHash: contentHash, Embedding: vector}
+	return nil
+}
+
+func (r *memoryReembedding) SetMedicationVector(ctx context.Context, id int, contentHash string, vector []float64) error {
+	r.medications[id] = Medication{ContentHash: contentHash, Embedding: vector}
+	return nil
+}
+
+func (r *memoryReembedding) Commit(ctx context.Context) error {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Every stored row needs a new vector before anything is swapped
+	for id := range s.pathologies {
+		if _, ok := r.pathologies[id]; !ok {
+			return fmt.Errorf("❌ Missing vector for pathology %d", id)
+		}
+	}
+	for id := range s.medications {
+		if _, ok := r.medications[id]; !ok {
+			return fmt.Errorf("❌ Missing vector for medication %d", id)
+		}
+	}
+
+	for id, p := range s.pathologies {
+		p.ContentHash, p.Embedding = r.pathologies[id].ContentHash, r.pathologies[id].Embedding
+		s.pathologies[id] = p
+	}
+	for id, m := range s.medications {
+		m.ContentHash, m.Embedding = r.medications[id].ContentHash, r.medications[id].Embedding
+		s.medications[id] = m
+	}
+	s.model = r.model
+	s.dimension = r.dimension
+	return nil
+}
+
+func (r *memoryReembedding) Abort(ctx context.Context) error {
+	return nil
+}
+
+// filterMedications returns the medications of a pathology ordered by ID.
+// The caller must hold the lock.
+func (s *MemoryStore) filterMedications(pathologyID int) []Medication {
+	var medications []Medication
+	for _, m := range sortedValues(s.medications) {
+		if pathologyID == 0 || m.PathologyID == pathologyID {
+			medications = append(medications, m)
+		}
+	}
+	return medications
+}
+
+// sortedValues copies a map into a slice ordered by key
+func sortedValues[T any](m map[int]T) []T {
+	keys := make([]int, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Ints(keys)
+
+	values := make([]T, 0, len(m))
+	for _, k := range keys {
+		values = append(values, m[k])
+	}
+	return values
+}
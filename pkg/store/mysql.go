@@ -0,0 +1,885 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	configPkg "github.com/colussim/go-mysql-ai/pkg/config"
+	_ "github.com/go-sql-driver/mysql"
+)
+
+// Columns shared by the SQL stores, in the order of medicationFields
+const medicationColumns = `
+		m.id,
+		m.pathologie_id,
+		p.name,
+		COALESCE(m.set_id, ''),
+		COALESCE(m.version, ''),
+		COALESCE(m.content_hash, ''),
+		m.drug_name,
+		m.inactive_ingredient,
+		m.purpose,
+		m.keep_out_of_reach_of_children,
+		m.warnings,
+		m.spl_product_data_elements,
+		m.dosage_and_administration,
+		m.pregnancy_or_breast_feeding,
+		m.package_label_principal_display_panel,
+		m.indications_and_usage,
+		(SELECT GROUP_CONCAT(i.name)
+		FROM medication_ingredients mi
+		JOIN ingredients i ON i.id = mi.ingredient_id
+		WHERE mi.medication_id = m.id)`
+
+// Columns written by UpsertMedication, in the order of medicationValues. The
+// embedding is handled separately.
+var medicationWriteColumns = []string{
+	"pathologie_id",
+	"set_id",
+	"version",
+	"content_hash",
+	"drug_name",
+	"inactive_ingredient",
+	"purpose",
+	"keep_out_of_reach_of_children",
+	"warnings",
+	"spl_product_data_elements",
+	"dosage_and_administration",
+	"pregnancy_or_breast_feeding",
+	"package_label_principal_display_panel",
+	"indications_and_usage",
+}
+
+func medicationValues(m *Medication) []any {
+	return []any{
+		m.PathologyID,
+		nullIfEmpty(m.SetID),
+		m.Version,
+		m.ContentHash,
+		m.DrugName,
+		m.InactiveIngredient,
+		m.Purpose,
+		m.KeepOutOfReachOfChildren,
+		m.Warnings,
+		m.SPLProductDataElements,
+		m.Dosage,
+		m.PregnancyOrBreastFeeding,
+		m.PackageLabel,
+		m.Indications,
+	}
+}
+
+// nullIfEmpty stores missing keys as NULL so they do not collide in UNIQUE indexes
+func nullIfEmpty(value string) any {
+	if value == "" {
+		return nil
+	}
+	return value
+}
+
+func medicationFields(m *Medication) []any {
+	return []any{
+		&m.ID,
+		&m.PathologyID,
+		&m.Pathology,
+		&m.SetID,
+		&m.Version,
+		&m.ContentHash,
+		&m.DrugName,
+		&m.InactiveIngredient,
+		&m.Purpose,
+		&m.KeepOutOfReachOfChildren,
+		&m.Warnings,
+		&m.SPLProductDataElements,
+		&m.Dosage,
+		&m.PregnancyOrBreastFeeding,
+		&m.PackageLabel,
+		&m.Indications,
+		(*ingredientsColumn)(&m.Ingredients),
+	}
+}
+
+// MySQLStore keeps the embeddings in MySQL VECTOR columns
+type MySQLStore struct {
+	db *sql.DB
+	// useDistance orders rows with DISTANCE() on the server instead of
+	// scoring every vector in Go
+	useDistance bool
+}
+
+func OpenMySQL(config *configPkg.Config) (*MySQLStore, error) {
+	db, err := OpenMySQLDB(config, MySQLDatabase(config))
+	if err != nil {
+		return nil, err
+	}
+	return NewMySQL(db, strings.EqualFold(config.Search.Ranking, "mysql")), nil
+}
+
+func NewMySQL(db *sql.DB, useDistance bool) *MySQLStore {
+	return &MySQLStore{db: db, useDistance: useDistance}
+}
+
+// DB returns the underlying connection pool
+func (s *MySQLStore) DB() *sql.DB {
+	return s.db
+}
+
+func (s *MySQLStore) Close() error {
+	return s.db.Close()
+}
+
+func (s *MySQLStore) UpsertPathology(ctx context.Context, p *Pathology) error {
+	embedding, err := Float64SliceToString(p.Embedding)
+	if err != nil {
+		return fmt.Errorf("❌ Error converting pathology embedding to string: %w", err)
+	}
+
+	// LAST_INSERT_ID(id) makes the existing row ID available on update
+	res, err := s.db.ExecContext(ctx, `
+	INSERT INTO pathologies (name, content_hash, embedding) VALUES (?, ?, STRING_TO_VECTOR(?)) AS new
+	ON DUPLICATE KEY UPDATE content_hash = new.content_hash, embedding = new.embedding, id = LAST_INSERT_ID(pathologies.id)`,
+		p.Name, p.ContentHash, embedding)
+	if err != nil {
+		return fmt.Errorf("❌ Error upserting into pathologies table: %w - size vector %d", err, len(p.Embedding))
+	}
+
+	id, err := res.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("❌ Error fetching pathology ID: %w", err)
+	}
+	p.ID = int(id)
+	return nil
+}
+
+func (s *MySQLStore) UpsertMedication(ctx context.Context, m *Medication) error {
+	return upsertMySQLMedication(ctx, s.db, m)
+}
+
+// mysqlInsertBatch is the number of rows of a multi-row insert
+const mysqlInsertBatch = 50
+
+// UpsertMedications writes the medications in a single transaction. New
+// labels are inserted with multi-row inserts, then their IDs are read back
+// by set ID since LAST_INSERT_ID() only reports the first row.
+func (s *MySQLStore) UpsertMedications(ctx context.Context, medications []*Medication) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("❌ Error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var inserts []*Medication
+	for _, m := range medications {
+		if m.ID > 0 || m.SetID == "" {
+			if err := upsertMySQLMedication(ctx, tx, m); err != nil {
+				return err
+			}
+			continue
+		}
+		if m.Embedding == nil {
+			return fmt.Errorf("❌ Missing embedding for new medication: %s", m.DrugName)
+		}
+		inserts = append(inserts, m)
+	}
+
+	for start := 0; start < len(inserts); start += mysqlInsertBatch {
+		if err := insertMySQLMedications(ctx, tx, inserts[start:min(start+mysqlInsertBatch, len(inserts))]); err != nil {
+			return err
+		}
+	}
+	for _, m := range inserts {
+		if err := linkMySQLIngredients(ctx, tx, m); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("❌ Error committing medications: %w", err)
+	}
+	return nil
+}
+
+// insertMySQLMedications inserts new labels with a single statement and sets their IDs
+func insertMySQLMedications(ctx context.Context, tx *sql.Tx, medications []*Medication) error {
+	columns := append(append([]string(nil), medicationWriteColumns...), "embedding")
+	row := "(" + strings.Repeat("?, ", len(medicationWriteColumns)) + "STRING_TO_VECTOR(?))"
+
+	rows := make([]string, len(medications))
+	keys := make([]string, len(medications))
+	var args, keyArgs []any
+	for i, m := range medications {
+		embedding, err := Float64SliceToString(m.Embedding)
+		if err != nil {
+			return fmt.Errorf("❌ Error converting medication embedding to string: %w", err)
+		}
+		rows[i] = row
+		args = append(append(args, medicationValues(m)...), embedding)
+		keys[i] = "(?, ?)"
+		keyArgs = append(keyArgs, m.PathologyID, m.SetID)
+	}
+
+	updates := make([]string, len(columns))
+	for i, column := range columns {
+		updates[i] = column + " = new." + column
+	}
+	query := "INSERT INTO medicationv (" + strings.Join(columns, ", ") + ") VALUES " + strings.Join(rows, ", ") + " AS new" +
+		" ON DUPLICATE KEY UPDATE " + strings.Join(updates, ", ")
+	if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+		return fmt.Errorf("❌ Error inserting medication data: %w", err)
+	}
+
+	result, err := tx.QueryContext(ctx, "SELECT id, pathologie_id, set_id FROM medicationv WHERE (pathologie_id, set_id) IN ("+strings.Join(keys, ", ")+")", keyArgs...)
+	if err != nil {
+		return fmt.Errorf("❌ Error fetching medication IDs: %w", err)
+	}
+	defer result.Close()
+
+	type key struct {
+		pathologyID int
+		setID       string
+	}
+	ids := make(map[key]int, len(medications))
+	for result.Next() {
+		var id int
+		var k key
+		if err := result.Scan(&id, &k.pathologyID, &k.setID); err != nil {
+			return fmt.Errorf("❌ Error scanning row: %w", err)
+		}
+		ids[k] = id
+	}
+	if err := result.Err(); err != nil {
+		return fmt.Errorf("❌ Error iterating over rows: %w", err)
+	}
+
+	for _, m := range medications {
+		m.ID = ids[key{m.PathologyID, m.SetID}]
+	}
+	return nil
+}
+
+// execer is implemented by *sql.DB and *sql.Tx
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+func upsertMySQLMedication(ctx context.Context, db execer, m *Medication) error {
+	columns := append([]string(nil), medicationWriteColumns...)
+	placeholders := make([]string, len(columns))
+	for i := range placeholders {
+		placeholders[i] = "?"
+	}
+	args := medicationValues(m)
+
+	if m.Embedding != nil {
+		embedding, err := Float64SliceToString(m.Embedding)
+		if err != nil {
+			return fmt.Errorf("❌ Error converting medication embedding to string: %w", err)
+		}
+		columns = append(columns, "embedding")
+		placeholders = append(placeholders, "STRING_TO_VECTOR(?)")
+		args = append(args, embedding)
+	}
+
+	if m.ID > 0 {
+		assignments := make([]string, len(columns))
+		for i, column := range columns {
+			assignments[i] = column + " = " + placeholders[i]
+		}
+		query := "UPDATE medicationv SET " + strings.Join(assignments, ", ") + " WHERE id = ?"
+		if _, err := db.ExecContext(ctx, query, append(args, m.ID)...); err != nil {
+			return fmt.Errorf("❌ Error updating medication data: %w - size vector %d", err, len(m.Embedding))
+		}
+		return linkMySQLIngredients(ctx, db, m)
+	}
+
+	if m.Embedding == nil {
+		return fmt.Errorf("❌ Missing embedding for new medication: %s", m.DrugName)
+	}
+
+	// The (pathologie_id, set_id) unique key turns a known label into an update
+	updates := make([]string, len(columns))
+	for i, column := range columns {
+		updates[i] = column + " = new." + column
+	}
+	query := "INSERT INTO medicationv (" + strings.Join(columns, ", ") + ") VALUES (" + strings.Join(placeholders, ", ") + ") AS new" +
+		" ON DUPLICATE KEY UPDATE " + strings.Join(updates, ", ") + ", id = LAST_INSERT_ID(medicationv.id)"
+
+	res, err := db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("❌ Error inserting medication data: %w - size vector %d", err, len(m.Embedding))
+	}
+
+	id, err := res.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("❌ Error fetching medication ID: %w", err)
+	}
+	m.ID = int(id)
+	return linkMySQLIngredients(ctx, db, m)
+}
+
+// linkMySQLIngredients replaces the ingredients of a medication, adding the
+// unknown ones to the ingredients table. medication_ingredients has no
+// foreign key to medicationv, which is swapped by RENAME TABLE when the
+// vectors are re-embedded: the links are removed with their medication.
+func linkMySQLIngredients(ctx context.Context, db execer, m *Medication) error {
+	if m.Ingredients == nil {
+		return nil
+	}
+	if _, err := db.ExecContext(ctx, "DELETE FROM medication_ingredients WHERE medication_id = ?", m.ID); err != nil {
+		return fmt.Errorf("❌ Error deleting data from medication_ingredients table: %w", err)
+	}
+
+	for _, name := range m.Ingredients {
+		// LAST_INSERT_ID(id) makes the existing row ID available on update
+		res, err := db.ExecContext(ctx, `
+		INSERT INTO ingredients (name) VALUES (?)
+		ON DUPLICATE KEY UPDATE id = LAST_INSERT_ID(id)`, name)
+		if err != nil {
+			return fmt.Errorf("❌ Error upserting into ingredients table: %w", err)
+		}
+		ingredientID, err := res.LastInsertId()
+		if err != nil {
+			return fmt.Errorf("❌ Error fetching ingredient ID: %w", err)
+		}
+		if _, err := db.ExecContext(ctx, "INSERT IGNORE INTO medication_ingredients (medication_id, ingredient_id) VALUES (?, ?)", m.ID, ingredientID); err != nil {
+			return fmt.Errorf("❌ Error inserting into medication_ingredients table: %w", err)
+		}
+	}
+	return nil
+}
+
+func (s *MySQLStore) SearchPathologies(ctx context.Context, vector []float64, limit int) ([]Pathology, error) {
+	if s.useDistance {
+		return s.searchPathologiesByDistance(ctx, vector, limit)
+	}
+
+	rows, err := s.db.QueryContext(ctx, "SELECT id, name, VECTOR_TO_STRING(embedding) FROM pathologies")
+	if err != nil {
+		return nil, fmt.Errorf("❌ Error querying pathologies: %w", err)
+	}
+	defer rows.Close()
+
+	var pathologies []Pathology
+	for rows.Next() {
+		var p Pathology
+		var embeddingString string
+
+		if err := rows.Scan(&p.ID, &p.Name, &embeddingString); err != nil {
+			return nil, fmt.Errorf("❌ Error scanning row: %w", err)
+		}
+
+		p.Embedding, err = StringToFloat64Slice(embeddingString)
+		if err != nil {
+			return nil, fmt.Errorf("❌ Error converting pathology embedding to float64 slice: %w", err)
+		}
+
+		pathologies = append(pathologies, p)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("❌ Error iterating over rows: %w", err)
+	}
+
+	return rankPathologies(pathologies, vector, limit), nil
+}
+
+// searchPathologiesByDistance lets the server order pathologies by cosine distance
+func (s *MySQLStore) searchPathologiesByDistance(ctx context.Context, vector []float64, limit int) ([]Pathology, error) {
+	queryVector, err := Float64SliceToString(vector)
+	if err != nil {
+		return nil, fmt.Errorf("❌ Error converting query embedding to string: %w", err)
+	}
+
+	query := `
+	SELECT id, name, DISTANCE(embedding, STRING_TO_VECTOR(?), 'COSINE') AS distance
+	FROM pathologies
+	ORDER BY distance`
+
+	args := []any{queryVector}
+	if limit > 0 {
+		query += " LIMIT ?"
+		args = append(args, limit)
+	}
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("❌ Error querying pathologies: %w", err)
+	}
+	defer rows.Close()
+
+	var pathologies []Pathology
+	for rows.Next() {
+		var p Pathology
+		var distance float64
+
+		if err := rows.Scan(&p.ID, &p.Name, &distance); err != nil {
+			return nil, fmt.Errorf("❌ Error scanning row: %w", err)
+		}
+		p.SimilarityScore = 1 - distance
+
+		pathologies = append(pathologies, p)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("❌ Error iterating over rows: %w", err)
+	}
+
+	return pathologies, nil
+}
+
+func (s *MySQLStore) SearchMedications(ctx context.Context, vector []float64, pathologyID int, limit int) ([]Medication, error) {
+	if s.useDistance {
+		return s.searchMedicationsByDistance(ctx, vector, pathologyID, limit)
+	}
+
+	query := "SELECT " + medicationColumns + `,
+		VECTOR_TO_STRING(m.embedding)
+	FROM medicationv m
+	JOIN pathologies p ON p.id = m.pathologie_id`
+
+	var args []any
+	if pathologyID > 0 {
+		query += " WHERE m.pathologie_id = ?"
+		args = append(args, pathologyID)
+	}
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("❌ Error querying medications for pathology: %w", err)
+	}
+	defer rows.Close()
+
+	var medications []Medication
+	for rows.Next() {
+		var med Medication
+		var embeddingString string
+
+		if err := rows.Scan(append(medicationFields(&med), &embeddingString)...); err != nil {
+			return nil, fmt.Errorf("❌ Error scanning row: %w", err)
+		}
+
+		// Convert embedding from string to float64 slice
+		med.Embedding, err = StringToFloat64Slice(embeddingString)
+		if err != nil {
+			return nil, fmt.Errorf("❌ Error converting medication embedding to float64 slice: %w", err)
+		}
+
+		medications = append(medications, med)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("❌ Error iterating over rows: %w", err)
+	}
+
+	return rankMedications(medications, vector, limit), nil
+}
+
+// searchMedicationsByDistance orders medications by DISTANCE() on the
+// server so only the best rows, without their vectors, are transferred
+func (s *MySQLStore) searchMedicationsByDistance(ctx context.Context, vector []float64, pathologyID int, limit int) ([]Medication, error) {
+	queryVector, err := Float64SliceToString(vector)
+	if err != nil {
+		return nil, fmt.Errorf("❌ Error converting query embedding to string: %w", err)
+	}
+
+	query := "SELECT " + medicationColumns + `,
+		DISTANCE(m.embedding, STRING_TO_VECTOR(?), 'COSINE') AS distance
+	FROM medicationv m
+	JOIN pathologies p ON p.id = m.pathologie_id`
+
+	args := []any{queryVector}
+	if pathologyID > 0 {
+		query += " WHERE m.pathologie_id = ?"
+		args = append(args, pathologyID)
+	}
+	query += " ORDER BY distance"
+	if limit > 0 {
+		query += " LIMIT ?"
+		args = append(args, limit)
+	}
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("❌ Error querying medications for pathology: %w", err)
+	}
+	defer rows.Close()
+
+	var medications []Medication
+	for rows.Next() {
+		var med Medication
+		var distance float64
+
+		if err := rows.Scan(append(medicationFields(&med), &distance)...); err != nil {
+			return nil, fmt.Errorf("❌ Error scanning row: %w", err)
+		}
+		med.SimilarityScore = 1 - distance
+
+		medications = append(medications, med)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("❌ Error iterating over rows: %w", err)
+	}
+
+	return medications, nil
+}
+
+func (s *MySQLStore) ListPathologies(ctx context.Context) ([]Pathology, error) {
+	rows, err := s.db.QueryContext(ctx, "SELECT id, name, COALESCE(content_hash, '') FROM pathologies ORDER BY name")
+	if err != nil {
+		return nil, fmt.Errorf("❌ Error querying pathologies: %w", err)
+	}
+	defer rows.Close()
+
+	var pathologies []Pathology
+	for rows.Next() {
+		var p Pathology
+		if err := rows.Scan(&p.ID, &p.Name, &p.ContentHash); err != nil {
+			return nil, fmt.Errorf("❌ Error scanning row: %w", err)
+		}
+		pathologies = append(pathologies, p)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("❌ Error iterating over rows: %w", err)
+	}
+
+	return pathologies, nil
+}
+
+func (s *MySQLStore) ListMedications(ctx context.Context, pathologyID int) ([]Medication, error) {
+	query := "SELECT " + medicationColumns + `
+	FROM medicationv m
+	JOIN pathologies p ON p.id = m.pathologie_id`
+
+	var args []any
+	if pathologyID > 0 {
+		query += " WHERE m.pathologie_id = ?"
+		args = append(args, pathologyID)
+	}
+	query += " ORDER BY m.id"
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("❌ Error querying medications: %w", err)
+	}
+	defer rows.Close()
+
+	var medications []Medication
+	for rows.Next() {
+		var med Medication
+		if err := rows.Scan(medicationFields(&med)...); err != nil {
+			return nil, fmt.Errorf("❌ Error scanning row: %w", err)
+		}
+		medications = append(medications, med)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("❌ Error iterating over rows: %w", err)
+	}
+
+	return medications, nil
+}
+
+func (s *MySQLStore) GetMedication(ctx context.Context, id int) (*Medication, error) {
+	return getSQLMedication(ctx, s.db, id)
+}
+
+// getSQLMedication reads a medication by ID, shared by the SQL stores
+func getSQLMedication(ctx context.Context, db *sql.DB, id int) (*Medication, error) {
+	query := "SELECT " + medicationColumns + `
+	FROM medicationv m
+	JOIN pathologies p ON p.id = m.pathologie_id
+	WHERE m.id = ?`
+
+	var med Medication
+	err := db.QueryRowContext(ctx, query, id).Scan(medicationFields(&med)...)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("❌ Error querying medication %d: %w", id, err)
+	}
+	return &med, nil
+}
+
+// UpsertInteractions writes the interactions in a single transaction
+func (s *MySQLStore) UpsertInteractions(ctx context.Context, interactions []*Interaction) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("❌ Error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	for _, i := range interactions {
+		if err := NormalizeInteraction(i); err != nil {
+			return err
+		}
+		_, err := tx.ExecContext(ctx, `
+		INSERT INTO interactions (ingredient_a, ingredient_b, severity, description, source) VALUES (?, ?, ?, ?, ?) AS new
+		ON DUPLICATE KEY UPDATE severity = new.severity, description = new.description, source = new.source`,
+			i.IngredientA, i.IngredientB, i.Severity, i.Description, nullIfEmpty(i.Source))
+		if err != nil {
+			return fmt.Errorf("❌ Error upserting into interactions table: %w", err)
+		}
+		if err := tx.QueryRowContext(ctx, "SELECT id FROM interactions WHERE ingredient_a = ? AND ingredient_b = ?", i.IngredientA, i.IngredientB).Scan(&i.ID); err != nil {
+			return fmt.Errorf("❌ Error reading interaction ID: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("❌ Error committing interactions: %w", err)
+	}
+	return nil
+}
+
+func (s *MySQLStore) ListInteractions(ctx context.Context) ([]Interaction, error) {
+	return listSQLInteractions(ctx, s.db)
+}
+
+// listSQLInteractions reads the interaction table, shared by the SQL stores
+func listSQLInteractions(ctx context.Context, db *sql.DB) ([]Interaction, error) {
+	rows, err := db.QueryContext(ctx, `
+	SELECT id, ingredient_a, ingredient_b, severity, COALESCE(description, ''), COALESCE(source, '')
+	FROM interactions
+	ORDER BY ingredient_a, ingredient_b`)
+	if err != nil {
+		return nil, fmt.Errorf("❌ Error querying interactions: %w", err)
+	}
+	defer rows.Close()
+
+	var interactions []Interaction
+	for rows.Next() {
+		var i Interaction
+		if err := rows.Scan(&i.ID, &i.IngredientA, &i.IngredientB, &i.Severity, &i.Description, &i.Source); err != nil {
+			return nil, fmt.Errorf("❌ Error scanning row: %w", err)
+		}
+		interactions = append(interactions, i)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("❌ Error iterating over rows: %w", err)
+	}
+
+	return interactions, nil
+}
+
+func (s *MySQLStore) DeletePathology(ctx context.Context, id int) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("❌ Error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// medicationv references pathologies without ON DELETE CASCADE
+	if _, err := tx.ExecContext(ctx, `
+	DELETE mi FROM medication_ingredients mi
+	JOIN medicationv m ON m.id = mi.medication_id
+	WHERE m.pathologie_id = ?`, id); err != nil {
+		return fmt.Errorf("❌ Error deleting data from medication_ingredients table: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, "DELETE FROM medicationv WHERE pathologie_id = ?", id); err != nil {
+		return fmt.Errorf("❌ Error deleting data from medicationv table: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, "DELETE FROM pathologies WHERE id = ?", id); err != nil {
+		return fmt.Errorf("❌ Error deleting data from pathologies table: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+func (s *MySQLStore) DeleteMedication(ctx context.Context, id int) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("❌ Error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM medication_ingredients WHERE medication_id = ?", id); err != nil {
+		return fmt.Errorf("❌ Error deleting data from medication_ingredients table: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, "DELETE FROM medicationv WHERE id = ?", id); err != nil {
+		return fmt.Errorf("❌ Error deleting data from medicationv table: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+func (s *MySQLStore) EmbeddingModel(ctx context.Context) (string, int, error) {
+	return readEmbeddingModel(ctx, s.db)
+}
+
+func (s *MySQLStore) SetEmbeddingModel(ctx context.Context, model string, dimension int) error {
+	_, err := s.db.ExecContext(ctx, `
+	INSERT INTO store_metadata (name, value) VALUES ('embedding_model', ?), ('embedding_dimension', ?) AS new
+	ON DUPLICATE KEY UPDATE value = new.value`, model, strconv.Itoa(dimension))
+	if err != nil {
+		return fmt.Errorf("❌ Error recording the embedding model: %w", err)
+	}
+	return nil
+}
+
+// VectorDimension reads the width of the VECTOR(n) embedding columns from
+// information_schema. Both tables must use the same width.
+func (s *MySQLStore) VectorDimension(ctx context.Context) (int, error) {
+	rows, err := s.db.QueryContext(ctx, `
+	SELECT TABLE_NAME, COLUMN_TYPE
+	FROM information_schema.COLUMNS
+	WHERE TABLE_SCHEMA = DATABASE() AND COLUMN_NAME = 'embedding' AND TABLE_NAME IN ('pathologies', 'medicationv')`)
+	if err != nil {
+		return 0, fmt.Errorf("❌ Error reading the embedding column type: %w", err)
+	}
+	defer rows.Close()
+
+	dimension := 0
+	for rows.Next() {
+		var table, columnType string
+		if err := rows.Scan(&table, &columnType); err != nil {
+			return 0, fmt.Errorf("❌ Error scanning row: %w", err)
+		}
+
+		var width int
+		if _, err := fmt.Sscanf(strings.ToLower(columnType), "vector(%d)", &width); err != nil {
+			return 0, fmt.Errorf("❌ Column %s.embedding is %s, not a VECTOR", table, columnType)
+		}
+		if dimension > 0 && width != dimension {
+			return 0, fmt.Errorf("❌ The embedding columns have different widths: VECTOR(%d) and VECTOR(%d)", dimension, width)
+		}
+		dimension = width
+	}
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("❌ Error iterating over rows: %w", err)
+	}
+	return dimension, nil
+}
+
+// readEmbeddingModel reads the model recorded in the store_metadata table
+func readEmbeddingModel(ctx context.Context, db *sql.DB) (string, int, error) {
+	rows, err := db.QueryContext(ctx, "SELECT name, value FROM store_metadata WHERE name IN ('embedding_model', 'embedding_dimension')")
+	if err != nil {
+		return "", 0, fmt.Errorf("❌ Error reading the embedding model: %w", err)
+	}
+	defer rows.Close()
+
+	var model string
+	var dimension int
+	for rows.Next() {
+		var name, value string
+		if err := rows.Scan(&name, &value); err != nil {
+			return "", 0, fmt.Errorf("❌ Error scanning row: %w", err)
+		}
+		switch name {
+		case "embedding_model":
+			model = value
+		case "embedding_dimension":
+			dimension, _ = strconv.Atoi(value)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return "", 0, fmt.Errorf("❌ Error iterating over rows: %w", err)
+	}
+	return model, dimension, nil
+}
+
+// mysqlReembedding writes the new vectors into shadow copies of the tables
+// (pathologies_next, medicationv_next, store_metadata_next) and swaps them
+// with a single RENAME TABLE, so readers see either all the old vectors or
+// all the new ones.
+type mysqlReembedding struct {
+	db        *sql.DB
+	model     string
+	dimension int
+}
+
+func (s *MySQLStore) BeginReembed(ctx context.Context, model string, dimension int) (Reembedding, error) {
+	r := &mysqlReembedding{db: s.db, model: model, dimension: dimension}
+
+	// Drop the leftovers of an interrupted run
+	if err := r.Abort(ctx); err != nil {
+		return nil, err
+	}
+
+	// The text columns are copied, the vectors are written by the caller
+	copyColumns := "id, " + strings.Join(medicationWriteColumns, ", ")
+	statements := []string{
+		"CREATE TABLE pathologies_next LIKE pathologies",
+		fmt.Sprintf("ALTER TABLE pathologies_next MODIFY embedding VECTOR(%d)", dimension),
+		"INSERT INTO pathologies_next (id, name, content_hash) SELECT id, name, content_hash FROM pathologies",
+		"CREATE TABLE medicationv_next LIKE medicationv",
+		fmt.Sprintf("ALTER TABLE medicationv_next MODIFY embedding VECTOR(%d), ADD FOREIGN KEY (pathologie_id) REFERENCES pathologies_next(id)", dimension),
+		"INSERT INTO medicationv_next (" + copyColumns + ") SELECT " + copyColumns + " FROM medicationv",
+		"CREATE TABLE store_metadata_next LIKE store_metadata",
+		"INSERT INTO store_metadata_next SELECT * FROM store_metadata",
+	}
+	for _, statement := range statements {
+		if _, err := s.db.ExecContext(ctx, statement); err != nil {
+			r.Abort(ctx)
+			return nil, fmt.Errorf("❌ Error creating the shadow tables: %w", err)
+		}
+	}
+	return r, nil
+}
+
+func (r *mysqlReembedding) SetPathologyVector(ctx context.Context, id int, contentHash string, vector []float64) error {
+	return r.setVector(ctx, "pathologies_next", id, contentHash, vector)
+}
+
+func (r *mysqlReembedding) SetMedicationVector(ctx context.Context, id int, contentHash string, vector []float64) error {
+	return r.setVector(ctx, "medicationv_next", id, contentHash, vector)
+}
+
+func (r *mysqlReembedding) setVector(ctx context.Context, table string, id int, contentHash string, vector []float64) error {
+	embedding, err := Float64SliceToString(vector)
+	if err != nil {
+		return fmt.Errorf("❌ Error converting embedding to string: %w", err)
+	}
+	if _, err := r.db.ExecContext(ctx, "UPDATE "+table+" SET embedding = STRING_TO_VECTOR(?), content_hash = ? WHERE id = ?", embedding, contentHash, id); err != nil {
+		return fmt.Errorf("❌ Error writing the new vector into %s: %w", table, err)
+	}
+	return nil
+}
+
+func (r *mysqlReembedding) Commit(ctx context.Context) error {
+	for _, table := range []string{"pathologies_next", "medicationv_next"} {
+		var missing int
+		if err := r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM "+table+" WHERE embedding IS NULL").Scan(&missing); err != nil {
+			return fmt.Errorf("❌ Error checking the new vectors of %s: %w", table, err)
+		}
+		if missing > 0 {
+			return fmt.Errorf("❌ %d rows of %s have no new vector", missing, table)
+		}
+	}
+
+	if _, err := r.db.ExecContext(ctx, `
+	INSERT INTO store_metadata_next (name, value) VALUES ('embedding_model', ?), ('embedding_dimension', ?) AS new
+	ON DUPLICATE KEY UPDATE value = new.value`, r.model, strconv.Itoa(r.dimension)); err != nil {
+		return fmt.Errorf("❌ Error recording the embedding model: %w", err)
+	}
+
+	statements := []string{
+		"DROP TABLE IF EXISTS medicationv_old, pathologies_old, store_metadata_old",
+		`RENAME TABLE
+			pathologies TO pathologies_old, pathologies_next TO pathologies,
+			medicationv TO medicationv_old, medicationv_next TO medicationv,
+			store_metadata TO store_metadata_old, store_metadata_next TO store_metadata`,
+		"DROP TABLE medicationv_old, pathologies_old, store_metadata_old",
+	}
+	for _, statement := range statements {
+		if _, err := r.db.ExecContext(ctx, statement); err != nil {
+			return fmt.Errorf("❌ Error swapping the shadow tables: %w", err)
+		}
+	}
+	return nil
+}
+
+func (r *mysqlReembedding) Abort(ctx context.Context) error {
+	if _, err := r.db.ExecContext(ctx, "DROP TABLE IF EXISTS medicationv_next, pathologies_next, store_metadata_next"); err != nil {
+		return fmt.Errorf("❌ Error dropping the shadow tables: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,227 @@
+package store
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"database/sql"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	configPkg "github.com/colussim/go-mysql-ai/pkg/config"
+	"github.com/go-sql-driver/mysql"
+)
+
+// DefaultMySQLDatabase is used when the mysql section names no database
+const DefaultMySQLDatabase = "health"
+
+// mysqlTLSName is the name the TLS configuration is registered under in the
+// mysql driver
+const mysqlTLSName = "go-mysql-ai"
+
+// MySQLDatabase returns the name of the configured database
+func MySQLDatabase(config *configPkg.Config) string {
+	if config.MySQL.Database == "" {
+		return DefaultMySQLDatabase
+	}
+	return config.MySQL.Database
+}
+
+// OpenMySQLDB connects to the configured MySQL server with database as the
+// default database, or without one when database is empty. Every MySQL
+// connection of the application is opened here.
+func OpenMySQLDB(config *configPkg.Config, database string) (*sql.DB, error) {
+	dsn, err := mysqlDSN(config, database)
+	if err != nil {
+		return nil, err
+	}
+	db, err := sql.Open("mysql", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("❌ Database connection error: %w", err)
+	}
+
+	if config.MySQL.MaxOpenConns > 0 {
+		db.SetMaxOpenConns(config.MySQL.MaxOpenConns)
+	}
+	if config.MySQL.MaxIdleConns > 0 {
+		db.SetMaxIdleConns(config.MySQL.MaxIdleConns)
+	}
+	if config.MySQL.ConnMaxLifetime > 0 {
+		db.SetConnMaxLifetime(time.Duration(config.MySQL.ConnMaxLifetime) * time.Second)
+	}
+
+	// check connexion
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("❌ Error verifying database connection: %w", err)
+	}
+	return db, nil
+}
+
+// mysqlDSN builds the driver DSN of the mysql section
+func mysqlDSN(config *configPkg.Config, database string) (string, error) {
+	password, err := config.MySQLPassword()
+	if err != nil {
+		return "", err
+	}
+
+	cfg := mysql.NewConfig()
+	cfg.User = config.MySQL.User
+	cfg.Passwd = password
+	cfg.DBName = database
+	cfg.ParseTime = true
+	cfg.Timeout = time.Duration(config.MySQL.ConnectTimeout) * time.Second
+	cfg.ReadTimeout = time.Duration(config.MySQL.ReadTimeout) * time.Second
+	cfg.WriteTimeout = time.Duration(config.MySQL.WriteTimeout) * time.Second
+
+	if config.MySQL.Socket != "" {
+		cfg.Net = "unix"
+		cfg.Addr = config.MySQL.Socket
+	} else {
+		cfg.Net = "tcp"
+		cfg.Addr = net.JoinHostPort(config.MySQL.Server, config.MySQL.Port)
+	}
+
+	tlsMode, err := mysqlTLS(config)
+	if err != nil {
+		return "", err
+	}
+	cfg.TLSConfig = tlsMode
+	if err := mysqlAuth(cfg, config.MySQL.TypeAuth); err != nil {
+		return "", err
+	}
+
+	dsn := cfg.FormatDSN()
+	if config.MySQL.Charset != "" {
+		// FormatDSN always writes parseTime, so the DSN already has parameters
+		dsn += "&charset=" + url.QueryEscape(config.MySQL.Charset)
+	}
+	return dsn, nil
+}
+
+// mysqlAuth selects the authentication plugins the driver may answer, from
+// type_auth:
+//
+//   - password (default): whichever of mysql_native_password,
+//     caching_sha2_password and sha256_password the server asks for
+//   - caching_sha2_password or sha256_password: only the SHA-256 plugins.
+//     Without TLS the driver fetches the RSA public key of the server.
+//   - cleartext (or mysql_clear_password, ldap, pam): the password is sent as
+//     is, for the LDAP and PAM server plugins. Only allowed over TLS
+//     (tls.mode required or stricter).
+func mysqlAuth(cfg *mysql.Config, typeAuth string) error {
+	switch strings.ToLower(typeAuth) {
+	case "", "password", "mysql_native_password":
+	case "caching_sha2_password", "sha256_password":
+		cfg.AllowNativePasswords = false
+	case "cleartext", "mysql_clear_password", "ldap", "pam":
+		if cfg.TLSConfig == "" || cfg.TLSConfig == "preferred" {
+			return fmt.Errorf("❌ type_auth %s sends the password in clear and needs tls.mode required or stricter", typeAuth)
+		}
+		cfg.AllowCleartextPasswords = true
+		cfg.AllowNativePasswords = false
+	default:
+		return fmt.Errorf("❌ Unknown MySQL type_auth: %s", typeAuth)
+	}
+	return nil
+}
+
+// mysqlTLS returns the value of the tls DSN parameter for the configured
+// mode, the MySQL --ssl-mode values:
+//
+//   - disabled (default): no TLS
+//   - preferred: TLS when the server supports it, without verification
+//   - required: TLS without verification of the server certificate
+//   - verify_ca: TLS, the server certificate must be signed by the CA
+//   - verify_identity: verify_ca, and the certificate must match the server name
+//
+// The CA defaults to the system pool. The client certificate and key are
+// sent with the last three modes.
+func mysqlTLS(config *configPkg.Config) (string, error) {
+	settings := config.MySQL.TLS
+	mode := strings.ReplaceAll(strings.ToLower(settings.Mode), "-", "_")
+
+	switch mode {
+	case "", "disabled":
+		return "", nil
+	case "preferred":
+		if settings.Cert != "" || settings.CA != "" {
+			return "", fmt.Errorf("❌ TLS mode preferred cannot use a CA or client certificate, use required or stricter")
+		}
+		return "preferred", nil
+	case "required", "verify_ca", "verify_identity":
+	default:
+		return "", fmt.Errorf("❌ Unknown TLS mode: %s", settings.Mode)
+	}
+
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+
+	if settings.CA != "" {
+		pem, err := os.ReadFile(settings.CA)
+		if err != nil {
+			return "", fmt.Errorf("❌ Error reading TLS CA file: %w", err)
+		}
+		tlsConfig.RootCAs = x509.NewCertPool()
+		if !tlsConfig.RootCAs.AppendCertsFromPEM(pem) {
+			return "", fmt.Errorf("❌ No certificate found in TLS CA file %s", settings.CA)
+		}
+	}
+
+	if settings.Cert != "" || settings.Key != "" {
+		cert, err := tls.LoadX509KeyPair(settings.Cert, settings.Key)
+		if err != nil {
+			return "", fmt.Errorf("❌ Error loading TLS client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	switch mode {
+	case "required":
+		tlsConfig.InsecureSkipVerify = true
+	case "verify_ca":
+		// The chain is verified by hand to skip the host name check
+		tlsConfig.InsecureSkipVerify = true
+		tlsConfig.VerifyPeerCertificate = verifyChain(tlsConfig.RootCAs)
+	case "verify_identity":
+		tlsConfig.ServerName = settings.ServerName
+		if tlsConfig.ServerName == "" {
+			tlsConfig.ServerName = config.MySQL.Server
+		}
+	}
+
+	if err := mysql.RegisterTLSConfig(mysqlTLSName, tlsConfig); err != nil {
+		return "", fmt.Errorf("❌ Error registering TLS configuration: %w", err)
+	}
+	return mysqlTLSName, nil
+}
+
+// verifyChain checks that the server certificate is signed by roots, or by
+// the system pool when roots is nil, whatever its host name
+func verifyChain(roots *x509.CertPool) func([][]byte, [][]*x509.Certificate) error {
+	return func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+		if len(rawCerts) == 0 {
+			return fmt.Errorf("❌ The server sent no TLS certificate")
+		}
+
+		certs := make([]*x509.Certificate, len(rawCerts))
+		for i, raw := range rawCerts {
+			cert, err := x509.ParseCertificate(raw)
+			if err != nil {
+				return fmt.Errorf("❌ Error parsing the server TLS certificate: %w", err)
+			}
+			certs[i] = cert
+		}
+
+		options := x509.VerifyOptions{Roots: roots, Intermediates: x509.NewCertPool()}
+		for _, cert := range certs[1:] {
+			options.Intermediates.AddCert(cert)
+		}
+		if _, err := certs[0].Verify(options); err != nil {
+			return fmt.Errorf("❌ Error verifying the server TLS certificate: %w", err)
+		}
+		return nil
+	}
+}
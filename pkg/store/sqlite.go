@@ -0,0 +1,501 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+
+	_ "modernc.org/sqlite"
+)
+
+const sqliteSchema = `
+CREATE TABLE IF NOT EXISTS pathologies (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name TEXT UNIQUE,
+    content_hash TEXT,
+    embedding TEXT
+);
+
+CREATE TABLE IF NOT EXISTS medicationv (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    pathologie_id INTEGER REFERENCES pathologies(id) ON DELETE CASCADE,
+    set_id TEXT,
+    version TEXT,
+    content_hash TEXT,
+    drug_name TEXT,
+    inactive_ingredient TEXT,
+    purpose TEXT,
+    keep_out_of_reach_of_children TEXT,
+    warnings TEXT,
+    spl_product_data_elements TEXT,
+    dosage_and_administration TEXT,
+    pregnancy_or_breast_feeding TEXT,
+    package_label_principal_display_panel TEXT,
+    indications_and_usage TEXT,
+    embedding TEXT,
+    UNIQUE (pathologie_id, set_id)
+);
+
+CREATE TABLE IF NOT EXISTS ingredients (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name TEXT NOT NULL UNIQUE
+);
+
+CREATE TABLE IF NOT EXISTS medication_ingredients (
+    medication_id INTEGER NOT NULL REFERENCES medicationv(id) ON DELETE CASCADE,
+    ingredient_id INTEGER NOT NULL REFERENCES ingredients(id),
+    PRIMARY KEY (medication_id, ingredient_id)
+);
+
+CREATE TABLE IF NOT EXISTS interactions (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    ingredient_a TEXT NOT NULL,
+    ingredient_b TEXT NOT NULL,
+    severity TEXT NOT NULL,
+    description TEXT,
+    source TEXT,
+    UNIQUE (ingredient_a, ingredient_b)
+);
+
+CREATE TABLE IF NOT EXISTS store_metadata (
+    name TEXT PRIMARY KEY,
+    value TEXT
+);
+`
+
+// SQLiteStore keeps the data in a single SQLite file. SQLite has no vector
+// type: embeddings are stored in the STRING_TO_VECTOR text format and
+// ranked in Go.
+type SQLiteStore struct {
+	db *sql.DB
+}
+
+func OpenSQLite(path string) (*SQLiteStore, error) {
+	if path == "" {
+		return nil, fmt.Errorf("❌ The sqlite store requires a path")
+	}
+
+	db, err := sql.Open("sqlite", "file:"+path+"?_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)")
+	if err != nil {
+		return nil, fmt.Errorf("❌ Database connection error: %w", err)
+	}
+	// A single connection serializes writers and keeps the pragmas applied
+	db.SetMaxOpenConns(1)
+
+	if _, err := db.Exec(sqliteSchema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("❌ Error creating sqlite schema: %w", err)
+	}
+
+	return &SQLiteStore{db: db}, nil
+}
+
+func (s *SQLiteStore) Close() error {
+	return s.db.Close()
+}
+
+func (s *SQLiteStore) UpsertPathology(ctx context.Context, p *Pathology) error {
+	embedding, err := Float64SliceToString(p.Embedding)
+	if err != nil {
+		return fmt.Errorf("❌ Error converting pathology embedding to string: %w", err)
+	}
+
+	err = s.db.QueryRowContext(ctx, `
+	INSERT INTO pathologies (name, content_hash, embedding) VALUES (?, ?, ?)
+	ON CONFLICT(name) DO UPDATE SET content_hash = excluded.content_hash, embedding = excluded.embedding
+	RETURNING id`, p.Name, p.ContentHash, embedding).Scan(&p.ID)
+	if err != nil {
+		return fmt.Errorf("❌ Error upserting into pathologies table: %w", err)
+	}
+	return nil
+}
+
+func (s *SQLiteStore) UpsertMedication(ctx context.Context, m *Medication) error {
+	return upsertSQLiteMedication(ctx, s.db, m)
+}
+
+// UpsertMedications writes the medications in a single transaction
+func (s *SQLiteStore) UpsertMedications(ctx context.Context, medications []*Medication) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("❌ Error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	for _, m := range medications {
+		if err := upsertSQLiteMedication(ctx, tx, m); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("❌ Error committing medications: %w", err)
+	}
+	return nil
+}
+
+func upsertSQLiteMedication(ctx context.Context, db execer, m *Medication) error {
+	columns := append([]string(nil), medicationWriteColumns...)
+	args := medicationValues(m)
+
+	if m.Embedding != nil {
+		embedding, err := Float64SliceToString(m.Embedding)
+		if err != nil {
+			return fmt.Errorf("❌ Error converting medication embedding to string: %w", err)
+		}
+		columns = append(columns, "embedding")
+		args = append(args, embedding)
+	}
+
+	if m.ID > 0 {
+		assignments := make([]string, len(columns))
+		for i, column := range columns {
+			assignments[i] = column + " = ?"
+		}
+		query := "UPDATE medicationv SET " + strings.Join(assignments, ", ") + " WHERE id = ?"
+		if _, err := db.ExecContext(ctx, query, append(args, m.ID)...); err != nil {
+			return fmt.Errorf("❌ Error updating medication data: %w", err)
+		}
+		return linkSQLiteIngredients(ctx, db, m)
+	}
+
+	if m.Embedding == nil {
+		return fmt.Errorf("❌ Missing embedding for new medication: %s", m.DrugName)
+	}
+
+	updates := make([]string, len(columns))
+	for i, column := range columns {
+		updates[i] = column + " = excluded." + column
+	}
+	query := "INSERT INTO medicationv (" + strings.Join(columns, ", ") + ") VALUES (" + strings.TrimSuffix(strings.Repeat("?, ", len(columns)), ", ") + ")" +
+		" ON CONFLICT(pathologie_id, set_id) DO UPDATE SET " + strings.Join(updates, ", ") +
+		" RETURNING id"
+
+	if err := db.QueryRowContext(ctx, query, args...).Scan(&m.ID); err != nil {
+		return fmt.Errorf("❌ Error inserting medication data: %w", err)
+	}
+	return linkSQLiteIngredients(ctx, db, m)
+}
+
+// linkSQLiteIngredients replaces the ingredients of a medication, adding the
+// unknown ones to the ingredients table
+func linkSQLiteIngredients(ctx context.Context, db execer, m *Medication) error {
+	if m.Ingredients == nil {
+		return nil
+	}
+	if _, err := db.ExecContext(ctx, "DELETE FROM medication_ingredients WHERE medication_id = ?", m.ID); err != nil {
+		return fmt.Errorf("❌ Error deleting data from medication_ingredients table: %w", err)
+	}
+
+	for _, name := range m.Ingredients {
+		var ingredientID int
+		err := db.QueryRowContext(ctx, `
+		INSERT INTO ingredients (name) VALUES (?)
+		ON CONFLICT(name) DO UPDATE SET name = excluded.name
+		RETURNING id`, name).Scan(&ingredientID)
+		if err != nil {
+			return fmt.Errorf("❌ Error upserting into ingredients table: %w", err)
+		}
+		if _, err := db.ExecContext(ctx, "INSERT OR IGNORE INTO medication_ingredients (medication_id, ingredient_id) VALUES (?, ?)", m.ID, ingredientID); err != nil {
+			return fmt.Errorf("❌ Error inserting into medication_ingredients table: %w", err)
+		}
+	}
+	return nil
+}
+
+func (s *SQLiteStore) SearchPathologies(ctx context.Context, vector []float64, limit int) ([]Pathology, error) {
+	rows, err := s.db.QueryContext(ctx, "SELECT id, name, embedding FROM pathologies")
+	if err != nil {
+		return nil, fmt.Errorf("❌ Error querying pathologies: %w", err)
+	}
+	defer rows.Close()
+
+	var pathologies []Pathology
+	for rows.Next() {
+		var p Pathology
+		var embeddingString string
+
+		if err := rows.Scan(&p.ID, &p.Name, &embeddingString); err != nil {
+			return nil, fmt.Errorf("❌ Error scanning row: %w", err)
+		}
+
+		p.Embedding, err = StringToFloat64Slice(embeddingString)
+		if err != nil {
+			return nil, fmt.Errorf("❌ Error converting pathology embedding to float64 slice: %w", err)
+		}
+
+		pathologies = append(pathologies, p)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("❌ Error iterating over rows: %w", err)
+	}
+
+	return rankPathologies(pathologies, vector, limit), nil
+}
+
+func (s *SQLiteStore) SearchMedications(ctx context.Context, vector []float64, pathologyID int, limit int) ([]Medication, error) {
+	query := "SELECT " + medicationColumns + `,
+		m.embedding
+	FROM medicationv m
+	JOIN pathologies p ON p.id = m.pathologie_id`
+
+	var args []any
+	if pathologyID > 0 {
+		query += " WHERE m.pathologie_id = ?"
+		args = append(args, pathologyID)
+	}
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("❌ Error querying medications for pathology: %w", err)
+	}
+	defer rows.Close()
+
+	var medications []Medication
+	for rows.Next() {
+		var med Medication
+		var embeddingString string
+
+		if err := rows.Scan(append(medicationFields(&med), &embeddingString)...); err != nil {
+			return nil, fmt.Errorf("❌ Error scanning row: %w", err)
+		}
+
+		med.Embedding, err = StringToFloat64Slice(embeddingString)
+		if err != nil {
+			return nil, fmt.Errorf("❌ Error converting medication embedding to float64 slice: %w", err)
+		}
+
+		medications = append(medications, med)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("❌ Error iterating over rows: %w", err)
+	}
+
+	return rankMedications(medications, vector, limit), nil
+}
+
+func (s *SQLiteStore) ListPathologies(ctx context.Context) ([]Pathology, error) {
+	rows, err := s.db.QueryContext(ctx, "SELECT id, name, COALESCE(content_hash, '') FROM pathologies ORDER BY name")
+	if err != nil {
+		return nil, fmt.Errorf("❌ Error querying pathologies: %w", err)
+	}
+	defer rows.Close()
+
+	var pathologies []Pathology
+	for rows.Next() {
+		var p Pathology
+		if err := rows.Scan(&p.ID, &p.Name, &p.ContentHash); err != nil {
+			return nil, fmt.Errorf("❌ Error scanning row: %w", err)
+		}
+		pathologies = append(pathologies, p)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("❌ Error iterating over rows: %w", err)
+	}
+
+	return pathologies, nil
+}
+
+func (s *SQLiteStore) ListMedications(ctx context.Context, pathologyID int) ([]Medication, error) {
+	query := "SELECT " + medicationColumns + `
+	FROM medicationv m
+	JOIN pathologies p ON p.id = m.pathologie_id`
+
+	var args []any
+	if pathologyID > 0 {
+		query += " WHERE m.pathologie_id = ?"
+		args = append(args, pathologyID)
+	}
+	query += " ORDER BY m.id"
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("❌ Error querying medications: %w", err)
+	}
+	defer rows.Close()
+
+	var medications []Medication
+	for rows.Next() {
+		var med Medication
+		if err := rows.Scan(medicationFields(&med)...); err != nil {
+			return nil, fmt.Errorf("❌ Error scanning row: %w", err)
+		}
+		medications = append(medications, med)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("❌ Error iterating over rows: %w", err)
+	}
+
+	return medications, nil
+}
+
+func (s *SQLiteStore) GetMedication(ctx context.Context, id int) (*Medication, error) {
+	return getSQLMedication(ctx, s.db, id)
+}
+
+// UpsertInteractions writes the interactions in a single transaction
+func (s *SQLiteStore) UpsertInteractions(ctx context.Context, interactions []*Interaction) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("❌ Error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	for _, i := range interactions {
+		if err := NormalizeInteraction(i); err != nil {
+			return err
+		}
+		err := tx.QueryRowContext(ctx, `
+		INSERT INTO interactions (ingredient_a, ingredient_b, severity, description, source) VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT(ingredient_a, ingredient_b) DO UPDATE SET severity = excluded.severity, description = excluded.description, source = excluded.source
+		RETURNING id`, i.IngredientA, i.IngredientB, i.Severity, i.Description, nullIfEmpty(i.Source)).Scan(&i.ID)
+		if err != nil {
+			return fmt.Errorf("❌ Error upserting into interactions table: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("❌ Error committing interactions: %w", err)
+	}
+	return nil
+}
+
+func (s *SQLiteStore) ListInteractions(ctx context.Context) ([]Interaction, error) {
+	return listSQLInteractions(ctx, s.db)
+}
+
+func (s *SQLiteStore) DeletePathology(ctx context.Context, id int) error {
+	// medicationv rows are removed by ON DELETE CASCADE
+	if _, err := s.db.ExecContext(ctx, "DELETE FROM pathologies WHERE id = ?", id); err != nil {
+		return fmt.Errorf("❌ Error deleting data from pathologies table: %w", err)
+	}
+	return nil
+}
+
+func (s *SQLiteStore) DeleteMedication(ctx context.Context, id int) error {
+	if _, err := s.db.ExecContext(ctx, "DELETE FROM medicationv WHERE id = ?", id); err != nil {
+		return fmt.Errorf("❌ Error deleting data from medicationv table: %w", err)
+	}
+	return nil
+}
+
+func (s *SQLiteStore) EmbeddingModel(ctx context.Context) (string, int, error) {
+	return readEmbeddingModel(ctx, s.db)
+}
+
+func (s *SQLiteStore) SetEmbeddingModel(ctx context.Context, model string, dimension int) error {
+	_, err := s.db.ExecContext(ctx, `
+	INSERT INTO store_metadata (name, value) VALUES ('embedding_model', ?), ('embedding_dimension', ?)
+	ON CONFLICT(name) DO UPDATE SET value = excluded.value`, model, strconv.Itoa(dimension))
+	if err != nil {
+		return fmt.Errorf("❌ Error recording the embedding model: %w", err)
+	}
+	return nil
+}
+
+// sqliteReembedding writes the new vectors into shadow columns, copied over
+// the current ones in a single transaction on Commit
+type sqliteReembedding struct {
+	db        *sql.DB
+	model     string
+	dimension int
+}
+
+var sqliteShadowColumns = []struct{ table, column string }{
+	{"pathologies", "embedding_next"},
+	{"pathologies", "content_hash_next"},
+	{"medicationv", "embedding_next"},
+	{"medicationv", "content_hash_next"},
+}
+
+func (s *SQLiteStore) BeginReembed(ctx context.Context, model string, dimension int) (Reembedding, error) {
+	r := &sqliteReembedding{db: s.db, model: model, dimension: dimension}
+
+	// Drop the leftovers of an interrupted run
+	if err := r.dropShadowColumns(ctx); err != nil {
+		return nil, err
+	}
+	for _, shadow := range sqliteShadowColumns {
+		if _, err := s.db.ExecContext(ctx, "ALTER TABLE "+shadow.table+" ADD COLUMN "+shadow.column+" TEXT"); err != nil {
+			return nil, fmt.Errorf("❌ Error adding shadow column %s.%s: %w", shadow.table, shadow.column, err)
+		}
+	}
+	return r, nil
+}
+
+func (r *sqliteReembedding) SetPathologyVector(ctx context.Context, id int, contentHash string, vector []float64) error {
+	return r.setVector(ctx, "pathologies", id, contentHash, vector)
+}
+
+func (r *sqliteReembedding) SetMedicationVector(ctx context.Context, id int, contentHash string, vector []float64) error {
+	return r.setVector(ctx, "medicationv", id, contentHash, vector)
+}
+
+func (r *sqliteReembedding) setVector(ctx context.Context, table string, id int, contentHash string, vector []float64) error {
+	embedding, err := Float64SliceToString(vector)
+	if err != nil {
+		return fmt.Errorf("❌ Error converting embedding to string: %w", err)
+	}
+	if _, err := r.db.ExecContext(ctx, "UPDATE "+table+" SET embedding_next = ?, content_hash_next = ? WHERE id = ?", embedding, contentHash, id); err != nil {
+		return fmt.Errorf("❌ Error writing the new vector into %s: %w", table, err)
+	}
+	return nil
+}
+
+func (r *sqliteReembedding) Commit(ctx context.Context) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("❌ Error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	for _, table := range []string{"pathologies", "medicationv"} {
+		var missing int
+		if err := tx.QueryRowContext(ctx, "SELECT COUNT(*) FROM "+table+" WHERE embedding_next IS NULL").Scan(&missing); err != nil {
+			return fmt.Errorf("❌ Error checking the new vectors of %s: %w", table, err)
+		}
+		if missing > 0 {
+			return fmt.Errorf("❌ %d rows of %s have no new vector", missing, table)
+		}
+		if _, err := tx.ExecContext(ctx, "UPDATE "+table+" SET embedding = embedding_next, content_hash = content_hash_next"); err != nil {
+			return fmt.Errorf("❌ Error swapping the vectors of %s: %w", table, err)
+		}
+	}
+	if _, err := tx.ExecContext(ctx, `
+	INSERT INTO store_metadata (name, value) VALUES ('embedding_model', ?), ('embedding_dimension', ?)
+	ON CONFLICT(name) DO UPDATE SET value = excluded.value`, r.model, strconv.Itoa(r.dimension)); err != nil {
+		return fmt.Errorf("❌ Error recording the embedding model: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("❌ Error committing the new vectors: %w", err)
+	}
+
+	return r.dropShadowColumns(ctx)
+}
+
+func (r *sqliteReembedding) Abort(ctx context.Context) error {
+	return r.dropShadowColumns(ctx)
+}
+
+func (r *sqliteReembedding) dropShadowColumns(ctx context.Context) error {
+	for _, shadow := range sqliteShadowColumns {
+		var exists bool
+		err := r.db.QueryRowContext(ctx, "SELECT COUNT(*) > 0 FROM pragma_table_info(?) WHERE name = ?", shadow.table, shadow.column).Scan(&exists)
+		if err != nil {
+			return fmt.Errorf("❌ Error reading the columns of %s: %w", shadow.table, err)
+		}
+		if !exists {
+			continue
+		}
+		if _, err := r.db.ExecContext(ctx, "ALTER TABLE "+shadow.table+" DROP COLUMN "+shadow.column); err != nil {
+			return fmt.Errorf("❌ Error dropping shadow column %s.%s: %w", shadow.table, shadow.column, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,109 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	configPkg "github.com/colussim/go-mysql-ai/pkg/config"
+)
+
+type Pathology struct {
+	ID              int       `json:"id"`
+	Name            string    `json:"name"`
+	ContentHash     string    `json:"content_hash,omitempty"`
+	Embedding       []float64 `json:"embedding,omitempty"`
+	SimilarityScore float64   `json:"similarity_score,omitempty"`
+}
+
+type Medication struct {
+	ID                       int    `json:"id"`
+	PathologyID              int    `json:"pathologie_id"`
+	Pathology                string `json:"pathology"`
+	SetID                    string `json:"set_id,omitempty"`
+	Version                  string `json:"version,omitempty"`
+	ContentHash              string `json:"content_hash,omitempty"`
+	DrugName                 string `json:"drug_name"`
+	InactiveIngredient       string `json:"inactive_ingredient"`
+	Purpose                  string `json:"purpose"`
+	KeepOutOfReachOfChildren string `json:"keep_out_of_reach_of_children"`
+	Warnings                 string `json:"warnings"`
+	SPLProductDataElements   string `json:"spl_product_data_elements"`
+	Dosage                   string `json:"dosage_and_administration"`
+	PregnancyOrBreastFeeding string `json:"pregnancy_or_breast_feeding"`
+	PackageLabel             string `json:"package_label"`
+	Indications              string `json:"indications_and_usage"`
+	// Canonical active ingredients, sorted
+	Ingredients []string `json:"ingredients"`
+	// Brands with the same active ingredients folded into this one by the
+	// retrieval, not stored
+	OtherBrands []string `json:"other_brands,omitempty"`
+	// Cautions for the patient the medication is retrieved for, not stored
+	Cautions        []string  `json:"cautions,omitempty"`
+	Embedding       []float64 `json:"embedding,omitempty"`
+	SimilarityScore float64   `json:"similarity_score,omitempty"`
+}
+
+// VectorStore persists pathologies and medications with their embeddings
+// and ranks them by cosine similarity to a query vector.
+//
+// Upserts set the ID of their argument. Pathologies are keyed on their name.
+// Medications are updated by ID when it is set, otherwise keyed on their
+// pathology and OpenFDA label set ID; a nil embedding on update keeps the
+// stored vector. Medications are linked to their canonical ingredients; nil
+// Ingredients on update keep the stored ones. UpsertMedications writes a
+// batch atomically where the store supports it. List and Get methods do not
+// return embeddings, and GetMedication returns nil for an unknown ID. A
+// pathologyID of 0 means every pathology. EmbeddingModel returns the model
+// recorded for the stored vectors, or an empty name when none was recorded.
+type VectorStore interface {
+	UpsertPathology(ctx context.Context, p *Pathology) error
+	UpsertMedication(ctx context.Context, m *Medication) error
+	UpsertMedications(ctx context.Context, medications []*Medication) error
+	SearchPathologies(ctx context.Context, vector []float64, limit int) ([]Pathology, error)
+	SearchMedications(ctx context.Context, vector []float64, pathologyID int, limit int) ([]Medication, error)
+	ListPathologies(ctx context.Context) ([]Pathology, error)
+	ListMedications(ctx context.Context, pathologyID int) ([]Medication, error)
+	GetMedication(ctx context.Context, id int) (*Medication, error)
+	DeletePathology(ctx context.Context, id int) error
+	DeleteMedication(ctx context.Context, id int) error
+	EmbeddingModel(ctx context.Context) (model string, dimension int, err error)
+	SetEmbeddingModel(ctx context.Context, model string, dimension int) error
+	Close() error
+}
+
+// VectorSizer is implemented by stores whose vector columns have a fixed
+// width. VectorDimension returns 0 when the width is unknown.
+type VectorSizer interface {
+	VectorDimension(ctx context.Context) (int, error)
+}
+
+// Reembedder is implemented by stores that can move their vectors to a new
+// embedding model while the current ones keep being served
+type Reembedder interface {
+	BeginReembed(ctx context.Context, model string, dimension int) (Reembedding, error)
+}
+
+// Reembedding stages the vectors of a new embedding model. Commit swaps all
+// of them in at once and records the model; Abort drops them.
+type Reembedding interface {
+	SetPathologyVector(ctx context.Context, id int, contentHash string, vector []float64) error
+	SetMedicationVector(ctx context.Context, id int, contentHash string, vector []float64) error
+	Commit(ctx context.Context) error
+	Abort(ctx context.Context) error
+}
+
+// Open returns the store selected by the "store" section of the configuration.
+// MySQL is used when no type is configured.
+func Open(config *configPkg.Config) (VectorStore, error) {
+	switch strings.ToLower(config.Store.Type) {
+	case "", "mysql":
+		return OpenMySQL(config)
+	case "memory":
+		return OpenMemory(config.Store.Path)
+	case "sqlite":
+		return OpenSQLite(config.Store.Path)
+	default:
+		return nil, fmt.Errorf("❌ Unknown store type: %s", config.Store.Type)
+	}
+}
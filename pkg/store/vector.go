@@ -0,0 +1,102 @@
+package store
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+func CosineSimilarity(vec1, vec2 []float64) float64 {
+	var dotProduct, normA, normB float64
+
+	// Vectors from different embedding models cannot be compared
+	if len(vec1) != len(vec2) {
+		return 0.0
+	}
+
+	for i := range vec1 {
+		dotProduct += vec1[i] * vec2[i]
+		normA += vec1[i] * vec1[i]
+		normB += vec2[i] * vec2[i]
+	}
+
+	// Avoid division by zero
+	if normA == 0 || normB == 0 {
+		return 0.0
+	}
+
+	return dotProduct / (math.Sqrt(normA) * math.Sqrt(normB))
+}
+
+// Float64SliceToString formats a vector for MySQL STRING_TO_VECTOR()
+func Float64SliceToString(values []float64) (string, error) {
+	for _, v := range values {
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return "", fmt.Errorf("invalid value detected in vector: %v", v)
+		}
+	}
+
+	var str []string
+	for _, v := range values {
+		str = append(str, fmt.Sprintf("%f", v))
+	}
+	return "[" + strings.Join(str, ",") + "]", nil
+}
+
+// StringToFloat64Slice parses the output of MySQL VECTOR_TO_STRING()
+func StringToFloat64Slice(embeddingString string) ([]float64, error) {
+	// Remove the brackets if present
+	embeddingString = strings.Trim(embeddingString, "[]")
+	if strings.TrimSpace(embeddingString) == "" {
+		return nil, nil
+	}
+
+	// Split the string by commas
+	parts := strings.Split(embeddingString, ",")
+
+	// Create a slice of float64
+	embedding := make([]float64, len(parts))
+
+	for i, part := range parts {
+		var value float64
+		_, err := fmt.Sscanf(part, "%f", &value)
+		if err != nil {
+			return nil, fmt.Errorf("❌ Error converting string to float64: %w", err)
+		}
+		embedding[i] = value
+
+	}
+
+	return embedding, nil
+}
+
+// rankPathologies scores pathologies against the query vector and keeps the
+// limit best ones
+func rankPathologies(pathologies []Pathology, vector []float64, limit int) []Pathology {
+	for i := range pathologies {
+		pathologies[i].SimilarityScore = CosineSimilarity(vector, pathologies[i].Embedding)
+	}
+	sort.Slice(pathologies, func(i, j int) bool {
+		return pathologies[i].SimilarityScore > pathologies[j].SimilarityScore
+	})
+	if limit > 0 && len(pathologies) > limit {
+		pathologies = pathologies[:limit]
+	}
+	return pathologies
+}
+
+// rankMedications scores medications against the query vector and keeps the
+// limit best ones
+func rankMedications(medications []Medication, vector []float64, limit int) []Medication {
+	for i := range medications {
+		medications[i].SimilarityScore = CosineSimilarity(vector, medications[i].Embedding)
+	}
+	sort.Slice(medications, func(i, j int) bool {
+		return medications[i].SimilarityScore > medications[j].SimilarityScore
+	})
+	if limit > 0 && len(medications) > limit {
+		medications = medications[:limit]
+	}
+	return medications
+}
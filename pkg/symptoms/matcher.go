@@ -0,0 +1,259 @@
+package symptoms
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/colussim/go-mysql-ai/pkg/store"
+)
+
+// Share of the words of a symptom a question must contain for a lexical match
+const lexicalMinimum = 0.5
+
+// SymptomMatch is a symptom of a pathology found in a question
+type SymptomMatch struct {
+	Symptom string  `json:"symptom"`
+	Score   float64 `json:"score" description:"Share of its words found in the question, or cosine similarity with the question"`
+	Method  string  `json:"method" description:"lexical or embedding"`
+}
+
+// Candidate is a pathology of a differential, scored on the share of its
+// symptoms the question describes
+type Candidate struct {
+	Pathology string         `json:"pathology"`
+	Score     float64        `json:"score"`
+	Named     bool           `json:"named" description:"Whether the question names the pathology"`
+	Matched   []SymptomMatch `json:"matched" description:"Symptoms of the pathology the question describes"`
+	Missing   []string       `json:"missing" description:"Symptoms of the pathology the question does not describe"`
+}
+
+// Matcher scores pathologies on their symptoms. It is safe for concurrent
+// use: WithEmbeddings returns a new matcher.
+type Matcher struct {
+	threshold   float64
+	names       []string
+	symptoms    map[string][]string
+	words       map[string][]string
+	namePattern map[string]*regexp.Regexp
+	model       string
+	vectors     map[string][][]float64
+}
+
+var (
+	wordPattern = regexp.MustCompile(`[a-z0-9]+`)
+	// Words that do not describe a symptom
+	stopWords = map[string]bool{
+		"a": true, "an": true, "the": true, "of": true, "to": true, "and": true, "or": true,
+		"in": true, "on": true, "with": true, "for": true, "go": true, "my": true, "i": true,
+		"have": true, "has": true, "am": true, "is": true, "are": true, "feel": true, "some": true,
+	}
+)
+
+// NewMatcher indexes the symptoms of the pathologies. A symptom matches a
+// question that contains most of its words, or whose embedding is at least
+// threshold similar to it.
+func NewMatcher(symptoms map[string][]string, threshold float64) *Matcher {
+	m := &Matcher{
+		threshold:   threshold,
+		symptoms:    make(map[string][]string),
+		words:       make(map[string][]string),
+		namePattern: make(map[string]*regexp.Regexp),
+	}
+	for name, list := range symptoms {
+		if len(list) == 0 {
+			continue
+		}
+		m.names = append(m.names, name)
+		m.symptoms[name] = list
+		m.namePattern[name] = regexp.MustCompile(`(?i)\b` + regexp.QuoteMeta(name) + `\b`)
+		for _, symptom := range list {
+			m.words[symptom] = symptomWords(symptom)
+		}
+	}
+	sort.Strings(m.names)
+	return m
+}
+
+// Len returns the number of pathologies with symptoms
+func (m *Matcher) Len() int {
+	return len(m.names)
+}
+
+// Model returns the embedding model of the symptoms, or an empty name when
+// they are not embedded
+func (m *Matcher) Model() string {
+	return m.model
+}
+
+// WithEmbeddings returns a matcher with the symptoms embedded by embed with
+// model
+func (m *Matcher) WithEmbeddings(ctx context.Context, model string, embed func(context.Context, []string) ([][]float64, error)) (*Matcher, error) {
+	var texts []string
+	for _, name := range m.names {
+		texts = append(texts, m.symptoms[name]...)
+	}
+
+	embedded := *m
+	embedded.model = model
+	embedded.vectors = make(map[string][][]float64)
+	if len(texts) == 0 {
+		return &embedded, nil
+	}
+	vectors, err := embed(ctx, texts)
+	if err != nil {
+		return nil, fmt.Errorf("❌ Error embedding the symptoms: %w", err)
+	}
+	if len(vectors) != len(texts) {
+		return nil, fmt.Errorf("❌ Got %d embeddings for %d symptoms", len(vectors), len(texts))
+	}
+	for _, name := range m.names {
+		n := len(m.symptoms[name])
+		embedded.vectors[name], vectors = vectors[:n], vectors[n:]
+	}
+	return &embedded, nil
+}
+
+// Rank returns the differential of a question: the pathologies with at
+// least one of their symptoms described, or named in the question, the
+// named ones first then by decreasing score. queryEmbedding may be nil to
+// only match words.
+func (m *Matcher) Rank(text string, queryEmbedding []float64) []Candidate {
+	words := make(map[string]bool)
+	for _, word := range symptomWords(text) {
+		words[word] = true
+	}
+
+	var candidates []Candidate
+	for _, name := range m.names {
+		candidate := Candidate{Pathology: name, Named: m.namePattern[name].MatchString(text), Matched: []SymptomMatch{}, Missing: []string{}}
+		var total float64
+		for i, symptom := range m.symptoms[name] {
+			match := SymptomMatch{Symptom: symptom}
+			if score := lexicalScore(m.words[symptom], words); score >= lexicalMinimum {
+				match.Score, match.Method = score, "lexical"
+			}
+			if vectors := m.vectors[name]; len(queryEmbedding) > 0 && len(vectors) > i {
+				if similarity := store.CosineSimilarity(queryEmbedding, vectors[i]); similarity >= m.threshold && similarity > match.Score {
+					match.Score, match.Method = similarity, "embedding"
+				}
+			}
+			if match.Score == 0 {
+				candidate.Missing = append(candidate.Missing, symptom)
+				continue
+			}
+			total += match.Score
+			candidate.Matched = append(candidate.Matched, match)
+		}
+		if len(candidate.Matched) == 0 && !candidate.Named {
+			continue
+		}
+		candidate.Score = total / float64(len(m.symptoms[name]))
+		candidates = append(candidates, candidate)
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if candidates[i].Named != candidates[j].Named {
+			return candidates[i].Named
+		}
+		return candidates[i].Score > candidates[j].Score
+	})
+	return candidates
+}
+
+// Close returns the candidates too close to the first one to choose between
+// them: the ones named in the question like it, or not named like it,
+// within margin of its score. It returns nil when the first one stands out.
+func Close(candidates []Candidate, margin float64) []Candidate {
+	if len(candidates) < 2 {
+		return nil
+	}
+	tied := []Candidate{candidates[0]}
+	for _, c := range candidates[1:] {
+		if c.Named == candidates[0].Named && candidates[0].Score-c.Score <= margin {
+			tied = append(tied, c)
+		}
+	}
+	if len(tied) == 1 {
+		return nil
+	}
+	return tied
+}
+
+// ClarifyingQuestion asks about the symptoms that tell close candidates
+// apart: the ones of each candidate that the others do not have
+func ClarifyingQuestion(tied []Candidate) string {
+	var names, options []string
+	for _, c := range tied {
+		names = append(names, c.Pathology)
+		var distinct []string
+		for _, symptom := range c.Missing {
+			shared := slices.ContainsFunc(tied, func(other Candidate) bool {
+				return other.Pathology != c.Pathology && (slices.Contains(other.Missing, symptom) || slices.ContainsFunc(other.Matched, func(s SymptomMatch) bool { return s.Symptom == symptom }))
+			})
+			if !shared && len(distinct) < 2 {
+				distinct = append(distinct, symptom)
+			}
+		}
+		if len(distinct) > 0 {
+			options = append(options, fmt.Sprintf("%s (%s)", strings.Join(distinct, " or "), c.Pathology))
+		}
+	}
+
+	question := fmt.Sprintf("Your symptoms could match %s.", joinOr(names))
+	if len(options) > 0 {
+		question += " To tell them apart: do you also have " + joinOr(options) + "?"
+	} else {
+		question += " Can you describe your symptoms in more detail?"
+	}
+	return question
+}
+
+// joinOr joins a list as "a, b or c"
+func joinOr(items []string) string {
+	if len(items) < 2 {
+		return strings.Join(items, "")
+	}
+	return strings.Join(items[:len(items)-1], ", ") + " or " + items[len(items)-1]
+}
+
+// lexicalScore returns the share of the words of a symptom found in a
+// question
+func lexicalScore(symptom []string, question map[string]bool) float64 {
+	if len(symptom) == 0 {
+		return 0
+	}
+	found := 0
+	for _, word := range symptom {
+		if question[word] {
+			found++
+		}
+	}
+	return float64(found) / float64(len(symptom))
+}
+
+// symptomWords returns the stems of the words of a text that describe a
+// symptom
+func symptomWords(text string) []string {
+	var words []string
+	for _, word := range wordPattern.FindAllString(strings.ToLower(text), -1) {
+		if !stopWords[word] {
+			words = append(words, stem(word))
+		}
+	}
+	return words
+}
+
+// stem removes the common inflections of an English word, so "sneezing"
+// and "sneezes" match "sneeze"
+func stem(word string) string {
+	for _, suffix := range []string{"ing", "ed", "es", "s", "e"} {
+		if strings.HasSuffix(word, suffix) && len(word)-len(suffix) >= 3 {
+			return strings.TrimSuffix(word, suffix)
+		}
+	}
+	return word
+}
@@ -0,0 +1,253 @@
+package symptoms
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"testing"
+)
+
+var pathologies = map[string][]string{
+	"cold":  {"runny nose", "sneezing", "sore throat"},
+	"fever": {"high temperature", "chills", "sweating"},
+	"rash":  {"red skin", "itching", "flaking skin"},
+}
+
+// summary writes a differential as "pathology score [matched symptom/method]"
+func summary(candidates []Candidate) []string {
+	list := []string{}
+	for _, c := range candidates {
+		var matched []string
+		for _, s := range c.Matched {
+			matched = append(matched, fmt.Sprintf("%s/%s %.2f", s.Symptom, s.Method, s.Score))
+		}
+		named := ""
+		if c.Named {
+			named = " named"
+		}
+		list = append(list, fmt.Sprintf("%s %.2f%s [%s]", c.Pathology, c.Score, named, strings.Join(matched, ", ")))
+	}
+	return list
+}
+
+func TestStem(t *testing.T) {
+	tests := []struct {
+		word string
+		want string
+	}{
+		{"sneezing", "sneez"},
+		{"sneezes", "sneez"},
+		{"sneeze", "sneez"},
+		{"coughed", "cough"},
+		{"aches", "ach"},
+		{"chills", "chill"},
+		{"nose", "nos"},
+		{"noses", "nos"},
+		{"red", "red"},
+		{"eyes", "eye"},
+		{"bed", "bed"},
+	}
+	for _, tt := range tests {
+		if got := stem(tt.word); got != tt.want {
+			t.Errorf("stem(%q) = %q, want %q", tt.word, got, tt.want)
+		}
+	}
+}
+
+func TestRank(t *testing.T) {
+	matcher := NewMatcher(pathologies, 0.9)
+	tests := []struct {
+		name     string
+		question string
+		want     []string
+	}{
+		{
+			name:     "nothing described",
+			question: "What is the best time to take a medication?",
+			want:     []string{},
+		},
+		{
+			name:     "all the words of a symptom",
+			question: "I have a runny nose",
+			want:     []string{"cold 0.33 [runny nose/lexical 1.00]"},
+		},
+		{
+			// lexicalMinimum is 0.5: one of the two words of a symptom is enough
+			name:     "half the words of a symptom",
+			question: "My nose",
+			want:     []string{"cold 0.17 [runny nose/lexical 0.50]"},
+		},
+		{
+			name:     "inflections",
+			question: "I keep sneezing and my throat is sore",
+			want:     []string{"cold 0.67 [sneezing/lexical 1.00, sore throat/lexical 1.00]"},
+		},
+		{
+			name:     "best described first",
+			question: "Chills, sweating and a runny nose",
+			want: []string{
+				"fever 0.67 [chills/lexical 1.00, sweating/lexical 1.00]",
+				"cold 0.33 [runny nose/lexical 1.00]",
+			},
+		},
+		{
+			name:     "named pathology first",
+			question: "Is it a cold? I have chills and I am sweating",
+			want: []string{
+				"cold 0.00 named []",
+				"fever 0.67 [chills/lexical 1.00, sweating/lexical 1.00]",
+			},
+		},
+		{
+			// "skin" also matches half of "flaking skin"
+			name:     "named pathology with its symptoms",
+			question: "I have a rash with itching and red skin, and chills",
+			want: []string{
+				"rash 0.83 named [red skin/lexical 1.00, itching/lexical 1.00, flaking skin/lexical 0.50]",
+				"fever 0.33 [chills/lexical 1.00]",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := summary(matcher.Rank(tt.question, nil))
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("Rank(%q) =\n%q\nwant\n%q", tt.question, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRankEmbeddings(t *testing.T) {
+	// "shivering" is close to "chills", not enough to "sweating"
+	axes := map[string][]float64{
+		"chills":   {1, 0, 0, 0},
+		"sweating": {0.6, 0.8, 0, 0},
+	}
+	embed := func(ctx context.Context, texts []string) ([][]float64, error) {
+		vectors := make([][]float64, len(texts))
+		for i, text := range texts {
+			if vector, ok := axes[text]; ok {
+				vectors[i] = vector
+			} else {
+				vectors[i] = []float64{0, 0, 0, 1}
+			}
+		}
+		return vectors, nil
+	}
+	matcher, err := NewMatcher(map[string][]string{"fever": pathologies["fever"]}, 0.9).WithEmbeddings(context.Background(), "test-model", embed)
+	if err != nil {
+		t.Fatalf("WithEmbeddings: %v", err)
+	}
+	if matcher.Model() != "test-model" || matcher.Len() != 1 {
+		t.Fatalf("got model %q and %d pathologies", matcher.Model(), matcher.Len())
+	}
+
+	shivering := []float64{1, 0.2, 0, 0}
+	tests := []struct {
+		name      string
+		question  string
+		embedding []float64
+		want      []string
+	}{
+		{"similar to a symptom", "I am shivering", shivering, []string{"fever 0.33 [chills/embedding 0.98]"}},
+		{"under the threshold", "I am shivering", []float64{0, 1, 0, 0}, []string{}},
+		{"lexical match kept when higher", "I have chills", shivering, []string{"fever 0.33 [chills/lexical 1.00]"}},
+		{"without embedding", "I am shivering", nil, []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := summary(matcher.Rank(tt.question, tt.embedding))
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("Rank(%q) =\n%q\nwant\n%q", tt.question, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClose(t *testing.T) {
+	tests := []struct {
+		name       string
+		candidates []Candidate
+		want       []string
+	}{
+		{"no candidates", nil, nil},
+		{"single candidate", []Candidate{{Pathology: "cold", Score: 0.3}}, nil},
+		{"leader stands out", []Candidate{{Pathology: "fever", Score: 0.67}, {Pathology: "cold", Score: 0.33}}, nil},
+		{"within the margin", []Candidate{{Pathology: "fever", Score: 0.4}, {Pathology: "cold", Score: 0.33}, {Pathology: "rash", Score: 0.1}}, []string{"fever", "cold"}},
+		{"at the margin", []Candidate{{Pathology: "fever", Score: 0.5}, {Pathology: "cold", Score: 0.4}}, []string{"fever", "cold"}},
+		{"named leader", []Candidate{{Pathology: "cold", Named: true}, {Pathology: "fever", Score: 0.05}}, nil},
+		{"several named", []Candidate{{Pathology: "cold", Named: true, Score: 0.33}, {Pathology: "fever", Named: true, Score: 0.33}, {Pathology: "rash", Score: 0.33}}, []string{"cold", "fever"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, c := range Close(tt.candidates, 0.1+1e-9) {
+				got = append(got, c.Pathology)
+			}
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("Close() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClarifyingQuestion(t *testing.T) {
+	matcher := NewMatcher(pathologies, 0.9)
+	tests := []struct {
+		name     string
+		question string
+		want     string
+	}{
+		{
+			name:     "distinct symptoms of each",
+			question: "I have a sore throat and itching",
+			want:     "Your symptoms could match cold or rash. To tell them apart: do you also have runny nose or sneezing (cold) or red skin or flaking skin (rash)?",
+		},
+		{
+			name:     "three candidates",
+			question: "Sneezing, chills and itching",
+			want:     "Your symptoms could match cold, fever or rash. To tell them apart: do you also have runny nose or sore throat (cold), high temperature or sweating (fever) or red skin or flaking skin (rash)?",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tied := Close(matcher.Rank(tt.question, nil), 0.1)
+			if tied == nil {
+				t.Fatalf("Close(Rank(%q)) = nil, want a tie", tt.question)
+			}
+			if got := ClarifyingQuestion(tied); got != tt.want {
+				t.Errorf("ClarifyingQuestion() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+
+	// Candidates without distinct symptoms get an open question
+	same := []Candidate{
+		{Pathology: "flu", Missing: []string{"cough"}},
+		{Pathology: "cold", Missing: []string{"cough"}},
+	}
+	if got, want := ClarifyingQuestion(same), "Your symptoms could match flu or cold. Can you describe your symptoms in more detail?"; got != want {
+		t.Errorf("ClarifyingQuestion() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestLexicalScore(t *testing.T) {
+	question := map[string]bool{"runny": true, "sor": true}
+	tests := []struct {
+		symptom []string
+		want    float64
+	}{
+		{[]string{"runny", "nos"}, 0.5},
+		{[]string{"sor", "throat"}, 0.5},
+		{[]string{"runny"}, 1},
+		{[]string{"chill"}, 0},
+		{nil, 0},
+	}
+	for _, tt := range tests {
+		if got := lexicalScore(tt.symptom, question); math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("lexicalScore(%v) = %v, want %v", tt.symptom, got, tt.want)
+		}
+	}
+}
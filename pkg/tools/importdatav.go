@@ -2,163 +2,189 @@ package tools
 
 import (
 	"context"
-	"database/sql"
-	"encoding/json"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
-	"io"
-	"math"
-	"net/http"
-	"net/url"
-	"os"
+	"slices"
+	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/briandowns/spinner"
 	configPkg "github.com/colussim/go-mysql-ai/pkg/config"
-	_ "github.com/go-sql-driver/mysql"
-	"github.com/ollama/ollama/api"
+	"github.com/colussim/go-mysql-ai/pkg/embedding"
+	"github.com/colussim/go-mysql-ai/pkg/openfda"
+	"github.com/colussim/go-mysql-ai/pkg/store"
 )
 
-type OpenFDAResponse struct {
-	Results []struct {
-		OpenFDA struct {
-			BrandName        []string `json:"brand_name"`
-			ActiveIngredient []string `json:"active_ingredient"`
-		} `json:"openfda"`
-		InactiveIngredient                []string `json:"inactive_ingredient"`
-		IndicationsAndUsage               []string `json:"indications_and_usage"`
-		Purpose                           []string `json:"purpose"`
-		KeepOutOfReachOfChildren          []string `json:"keep_out_of_reach_of_children"`
-		Warnings                          []string `json:"warnings"`
-		SPLProductDataElements            []string `json:"spl_product_data_elements"`
-		DosageAndAdministration           []string `json:"dosage_and_administration"`
-		PregnancyOrBreastFeeding          []string `json:"pregnancy_or_breast_feeding"`
-		PackageLabelPRincipalDisplayPanel []string `json:"package_label_principal_display_panel"`
-	} `json:"results"`
-}
-
 var (
 	FALSE = false
 	TRUE  = true
 )
 
-func generateEmbedding(text, model string) []float64 {
+// newOpenFDAClient configures the OpenFDA client from the "openfda" section
+func newOpenFDAClient(config *configPkg.Config) *openfda.Client {
+	return openfda.NewClient(openfda.Options{
+		URL:               config.OpenFDA.URL,
+		APIKey:            config.OpenFDA.APIKey,
+		PageSize:          config.OpenFDA.PageSize,
+		MaxResults:        config.OpenFDA.MaxResults,
+		RequestsPerMinute: config.OpenFDA.RequestsPerMinute,
+		MaxRetries:        config.OpenFDA.MaxRetries,
+		Timeout:           time.Duration(config.OpenFDA.Timeout) * time.Second,
+	})
+}
 
-	configPkg.InitLogger()
-	// Get the Ollama host from the environment variable or use the default local host
-	ollamaHost := os.Getenv("OLLAMA_HOST")
-	if ollamaHost == "" {
-		ollamaHost = "http://localhost:11434" // Default Ollama local server URL
-	}
+// ImportStats counts what an import did to the medications of a pathology
+type ImportStats struct {
+	New       int           `json:"new"`
+	Updated   int           `json:"updated"`
+	Unchanged int           `json:"unchanged"`
+	Removed   int           `json:"removed"`
+	Embedded  int           `json:"embedded"`
+	Duration  time.Duration `json:"duration"`
+	Labels    []LabelStatus `json:"-"`
+}
 
-	url, _ := url.Parse(ollamaHost)
-	// Create a new Ollama client with the local host
-	client := api.NewClient(url, http.DefaultClient)
+func (s *ImportStats) add(other ImportStats) {
+	s.New += other.New
+	s.Updated += other.Updated
+	s.Unchanged += other.Unchanged
+	s.Removed += other.Removed
+	s.Embedded += other.Embedded
+	s.Duration += other.Duration
+}
 
-	// Use the mxbai-embed-large:latest model to generate embeddings
+// ImportReport summarizes an import run
+type ImportReport struct {
+	RunID     string            `json:"run_id"`
+	Succeeded []string          `json:"succeeded"`
+	Skipped   []string          `json:"skipped"`
+	Failed    map[string]string `json:"failed"`
+	Totals    ImportStats       `json:"totals"`
+}
 
-	req := &api.EmbeddingRequest{
-		Model:  model,
-		Prompt: text,
-	}
-	subSpinner1 := spinner.New(spinner.CharSets[9], 100*time.Millisecond)
-	subSpinner1.Prefix = "           Embedding generation... "
-	subSpinner1.Start()
-	resp, err := client.Embeddings(context.Background(), req)
-	if err != nil {
-		subSpinner1.Stop()
-		fmt.Println()
-		configPkg.Log.Fatalf("❌ Error generating embedding: %v", err)
+// contentHash fingerprints the text that is stored and embedded, together
+// with the embedding model, so unchanged records are not embedded again.
+func contentHash(model string, parts ...string) string {
+	h := sha256.New()
+	h.Write([]byte(model))
+	for _, part := range parts {
+		h.Write([]byte{0})
+		h.Write([]byte(part))
 	}
-	subSpinner1.Stop()
-
-	return resp.Embedding
-
+	return hex.EncodeToString(h.Sum(nil))
 }
 
-func fetchMedications(pathology string) (OpenFDAResponse, error) {
-	encodedPathology := url.QueryEscape(pathology)
-	url := fmt.Sprintf("https://api.fda.gov/drug/label.json?search=indications_and_usage:%s+AND+_exists_:openfda.brand_name&limit=50", encodedPathology)
+// pathologyText is the text embedded for a pathology
+func pathologyText(pathology string, details configPkg.PathologyDetail) string {
+	return fmt.Sprintf("%s. Description: %s. Symptoms: %s. Treatments: %s.",
+		pathology,
+		details.Description,
+		strings.Join(details.Symptoms, ", "),
+		strings.Join(details.Treatments, ", "))
+}
 
-	resp, err := http.Get(url)
-	if err != nil {
-		return OpenFDAResponse{}, fmt.Errorf("❌ Error fetching medications: %w", err)
-	}
-	defer resp.Body.Close()
+// medicationText is the text embedded for a medication, built from its
+// stored columns so stored labels can be embedded again
+func medicationText(pathology string, m *store.Medication) string {
+	//text := fmt.Sprintf("Medication: %s. Indications: %s. Purpose: %s. Active Ingredients: %s. Dosage: %s. Warnings: %s. Package Label: %s",
+	return fmt.Sprintf("For this pathology: %s,Medication: %s. Indications: %s. Purpose: %s. Dosage: %s. Warnings: %s. Package Label: %s",
+		pathology,
+		m.DrugName,
+		m.Indications,
+		m.Purpose,
+		m.Dosage,
+		m.Warnings,
+		m.PackageLabel,
+	)
+}
 
-	// Check if the response status code is 200 (OK)
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body) // Read the body for debugging purposes
-		return OpenFDAResponse{}, fmt.Errorf("❌ API returned status %d: %s", resp.StatusCode, string(body))
-	}
+func medicationHash(model, text string, m *store.Medication) string {
+	return contentHash(model, text, m.DrugName, m.InactiveIngredient, m.Purpose, m.KeepOutOfReachOfChildren,
+		m.Warnings, m.SPLProductDataElements, m.Dosage, m.PregnancyOrBreastFeeding, m.PackageLabel, m.Indications)
+}
 
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return OpenFDAResponse{}, fmt.Errorf("❌ Error reading response body: %w", err)
-	}
+const (
+	defaultWorkers   = 4
+	defaultBatchSize = 16
+)
 
-	var data OpenFDAResponse
-	if err := json.Unmarshal(body, &data); err != nil {
-		return OpenFDAResponse{}, fmt.Errorf("❌ Error unmarshalling response: %w", err)
-	}
-	return data, nil
-}
+// Importer writes pathologies and their drug labels to a store
+type Importer struct {
+	Store      store.VectorStore
+	Embeddings *embedding.Service
 
-func float64SliceToString(values []float64) (string, error) {
-	for _, v := range values {
-		if math.IsNaN(v) || math.IsInf(v, 0) {
-			return "", fmt.Errorf("invalid value detected in vector: %v", v)
-		}
-	}
+	// Workers is the number of concurrent embedding requests, each one
+	// embedding up to BatchSize labels
+	Workers   int
+	BatchSize int
 
-	var str []string
-	for _, v := range values {
-		str = append(str, fmt.Sprintf("%f", v))
-	}
-	return "[" + strings.Join(str, ",") + "]", nil
+	// Progress is called after each embedded batch of a pathology
+	Progress func(pathology string, done, total int, elapsed time.Duration)
 }
 
-func InsertData(db *sql.DB, pathology string, details configPkg.PathologyDetail, data OpenFDAResponse, model string) error {
+// InsertData synchronizes one pathology and its OpenFDA labels with the store.
+// previous is the stored pathology, or the zero value on the first import.
+// Labels are keyed on their set ID: unchanged labels are skipped, changed
+// ones are embedded again and labels that are no longer returned are removed.
+// The labels are written in a single transaction once they are all embedded.
+func (im *Importer) InsertData(ctx context.Context, pathology string, details configPkg.PathologyDetail, data openfda.Response, previous store.Pathology) (ImportStats, error) {
 
-	embeddingText := fmt.Sprintf("%s. Description: %s. Symptoms: %s. Treatments: %s.",
-		pathology,
-		details.Description,
-		strings.Join(details.Symptoms, ", "),
-		strings.Join(details.Treatments, ", "))
+	var stats ImportStats
+	start := time.Now()
 
-	pathologyEmbedding := generateEmbedding(embeddingText, model)
+	embeddingText := pathologyText(pathology, details)
 
-	// Convert slice to string
-	pathologyEmbeddingString, err := float64SliceToString(pathologyEmbedding)
-	if err != nil {
-		return fmt.Errorf("❌ Error converting pathology embedding to string: %w", err)
+	record := store.Pathology{ID: previous.ID, Name: pathology, ContentHash: contentHash(im.Embeddings.Model, embeddingText)}
+	if previous.ID == 0 || previous.ContentHash != record.ContentHash {
+		embedding, err := im.Embeddings.EmbedText(ctx, embeddingText)
+		if err != nil {
+			return stats, fmt.Errorf("❌ Error generating embedding: %w", err)
+		}
+		record.Embedding = embedding
+		if err := im.Store.UpsertPathology(ctx, &record); err != nil {
+			return stats, err
+		}
 	}
 
-	subSpinner1 := spinner.New(spinner.CharSets[9], 100*time.Millisecond)
-	subSpinner1.Prefix = "           INSERT INTO pathologies... "
-	subSpinner1.Start()
-	size := len(pathologyEmbeddingString)
-	// Insert vector using STRING_TO_VECTOR
-	_, err = db.Exec("INSERT INTO pathologies (name, embedding) VALUES (?, STRING_TO_VECTOR(?))", pathology, pathologyEmbeddingString)
+	stored, err := im.Store.ListMedications(ctx, record.ID)
 	if err != nil {
-		subSpinner1.Stop()
-		return fmt.Errorf("❌ Error inserting into pathologies table: %w - size vector %d: ", err, size)
+		return stats, err
 	}
-	subSpinner1.Stop()
-
-	subSpinner1.Prefix = "           INSERT INTO medicationv... "
-	var pathologyID int
-	err = db.QueryRow("SELECT id FROM pathologies WHERE name = ?", pathology).Scan(&pathologyID)
-	if err != nil {
-		return fmt.Errorf("❌ Error fetching pathology ID: %w", err)
+	// Medications imported before labels were keyed on their set ID have
+	// none: they are all replaced by this import
+	existing := make(map[string]store.Medication, len(stored))
+	var legacy []store.Medication
+	for _, m := range stored {
+		if m.SetID == "" {
+			legacy = append(legacy, m)
+			continue
+		}
+		existing[m.SetID] = m
 	}
-	subSpinner1.Start()
+	seen := make(map[string]bool, len(data.Results))
+
+	// Labels to write, and among them the ones to embed with their text
+	var writes, pending []*store.Medication
+	var texts []string
 
 	for _, result := range data.Results {
 		if len(result.OpenFDA.BrandName) == 0 {
 			continue
 		}
 
+		// Labels are identified by their set ID, which is stable across versions
+		setID := result.SetID
+		if setID == "" {
+			setID = result.ID
+		}
+		if setID == "" || seen[setID] {
+			continue
+		}
+		seen[setID] = true
+
 		medicament := result.OpenFDA.BrandName[0]
 
 		// Information retrieval and concatenation
@@ -173,100 +199,211 @@ func InsertData(db *sql.DB, pathology string, details configPkg.PathologyDetail,
 		pregnancy := strings.Join(result.PregnancyOrBreastFeeding, ". ")
 		packageLabel := strings.Join(result.PackageLabelPRincipalDisplayPanel, ". ")
 
-		//text := fmt.Sprintf("Medication: %s. Indications: %s. Purpose: %s. Active Ingredients: %s. Dosage: %s. Warnings: %s. Package Label: %s",
-		text := fmt.Sprintf("For this pathology: %s,Medication: %s. Indications: %s. Purpose: %s. Dosage: %s. Warnings: %s. Package Label: %s",
-			pathology,
-			medicament,
-			strings.Join(result.IndicationsAndUsage, ", "),
-			strings.Join(result.Purpose, ", "),
-			strings.Join(result.DosageAndAdministration, ", "),
-			strings.Join(result.Warnings, ", "),
-			packageLabel,
-		)
+		// Brands of the same drug are related through their active ingredients
+		activeIngredients := result.OpenFDA.ActiveIngredient
+		if len(activeIngredients) == 0 {
+			activeIngredients = result.OpenFDA.SubstanceName
+		}
 
-		medEmbedding := generateEmbedding(text, model)
-		// CConvert slice to string
-		medEmbeddingString, err := float64SliceToString(medEmbedding)
+		medication := &store.Medication{
+			PathologyID:              record.ID,
+			SetID:                    setID,
+			Version:                  result.Version,
+			DrugName:                 medicament,
+			InactiveIngredient:       inactiveIngredients,
+			Purpose:                  purpose,
+			KeepOutOfReachOfChildren: keepOutOfReach,
+			Warnings:                 warnings,
+			SPLProductDataElements:   splProductData,
+			Dosage:                   dosage,
+			PregnancyOrBreastFeeding: pregnancy,
+			PackageLabel:             packageLabel,
+			Indications:              indications,
+			Ingredients:              store.CanonicalIngredients(activeIngredients),
+		}
+		text := medicationText(pathology, medication)
+		medication.ContentHash = medicationHash(im.Embeddings.Model, text, medication)
+
+		previousMedication, known := existing[setID]
+		switch {
+		case known && previousMedication.ContentHash == medication.ContentHash && previousMedication.Version == medication.Version &&
+			slices.Equal(previousMedication.Ingredients, medication.Ingredients):
+			stats.Unchanged++
+			stats.Labels = append(stats.Labels, LabelStatus{SetID: setID, Status: "unchanged"})
+			continue
+		case known && previousMedication.ContentHash == medication.ContentHash:
+			// Only the label version or the ingredients moved: keep the
+			// stored vector
+			medication.ID = previousMedication.ID
+			stats.Updated++
+			stats.Labels = append(stats.Labels, LabelStatus{SetID: setID, Status: "updated"})
+		case known:
+			medication.ID = previousMedication.ID
+			pending = append(pending, medication)
+			texts = append(texts, text)
+			stats.Updated++
+			stats.Labels = append(stats.Labels, LabelStatus{SetID: setID, Status: "updated"})
+		default:
+			pending = append(pending, medication)
+			texts = append(texts, text)
+			stats.New++
+			stats.Labels = append(stats.Labels, LabelStatus{SetID: setID, Status: "new"})
+		}
+		writes = append(writes, medication)
+	}
 
-		size := len(pathologyEmbeddingString)
+	embeddings, err := im.embed(ctx, pathology, texts, start)
+	if err != nil {
+		return stats, err
+	}
+	for i, medication := range pending {
+		medication.Embedding = embeddings[i]
+	}
+	stats.Embedded = len(texts)
 
-		if err != nil {
-			return fmt.Errorf("❌ Error converting medication embedding to string: %w", err)
-		}
+	if err := im.Store.UpsertMedications(ctx, writes); err != nil {
+		return stats, err
+	}
 
-		// Insertion dans la base de données
-		_, err = db.Exec(`INSERT INTO medicationv (
-            pathologie_id,
-			drug_name,
-            inactive_ingredient,
-            purpose,
-            keep_out_of_reach_of_children,
-            warnings,
-            spl_product_data_elements,
-            dosage_and_administration,
-            pregnancy_or_breast_feeding,
-            package_label_principal_display_panel,
-            indications_and_usage,
-            embedding
-        ) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, STRING_TO_VECTOR(?))`,
-			pathologyID,
-			medicament,
-			inactiveIngredients,
-			purpose,
-			keepOutOfReach,
-			warnings,
-			splProductData,
-			dosage,
-			pregnancy,
-			packageLabel,
-			indications,
-			medEmbeddingString,
-		)
-		if err != nil {
-			subSpinner1.Stop()
-			return fmt.Errorf("❌ Error inserting medication data: %w - size vector %d: ", err, size)
+	// Labels that OpenFDA no longer returns for this pathology
+	for setID, m := range existing {
+		if seen[setID] {
+			continue
 		}
+		if err := im.Store.DeleteMedication(ctx, m.ID); err != nil {
+			return stats, err
+		}
+		stats.Removed++
+		stats.Labels = append(stats.Labels, LabelStatus{SetID: setID, Status: "removed"})
 	}
-	subSpinner1.Stop()
-	return nil
+	for _, m := range legacy {
+		if err := im.Store.DeleteMedication(ctx, m.ID); err != nil {
+			return stats, err
+		}
+		stats.Removed++
+		stats.Labels = append(stats.Labels, LabelStatus{SetID: "", Status: "removed"})
+	}
+
+	stats.Duration = time.Since(start)
+	return stats, nil
 }
 
-func initDatabase(db *sql.DB) error {
+// embed generates the embeddings of texts with a bounded pool of workers.
+// The first failure cancels the requests still running.
+func (im *Importer) embed(ctx context.Context, pathology string, texts []string, start time.Time) ([][]float64, error) {
+	embeddings := make([][]float64, len(texts))
+	if len(texts) == 0 {
+		return embeddings, nil
+	}
 
-	// Delete all data from the medicationv table
-	_, err := db.Exec("DELETE FROM medicationv")
-	if err != nil {
-		return fmt.Errorf("❌ Error deleting data from medicationv table: %w", err)
+	workers := im.Workers
+	if workers <= 0 {
+		workers = defaultWorkers
+	}
+	batchSize := im.BatchSize
+	if batchSize <= 0 {
+		batchSize = defaultBatchSize
+	}
+	batches := (len(texts) + batchSize - 1) / batchSize
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		mu       sync.Mutex
+		wg       sync.WaitGroup
+		firstErr error
+		done     int
+	)
+	offsets := make(chan int)
+	for range min(workers, batches) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for offset := range offsets {
+				end := min(offset+batchSize, len(texts))
+				vectors, err := im.Embeddings.Embed(ctx, texts[offset:end])
+				if err == nil && len(vectors) != end-offset {
+					err = fmt.Errorf("❌ Expected %d embeddings, got %d", end-offset, len(vectors))
+				}
+
+				mu.Lock()
+				if err != nil {
+					if firstErr == nil {
+						firstErr = fmt.Errorf("❌ Error generating embedding: %w", err)
+						cancel()
+					}
+					mu.Unlock()
+					continue
+				}
+				copy(embeddings[offset:end], vectors)
+				done += end - offset
+				if im.Progress != nil {
+					im.Progress(pathology, done, len(texts), time.Since(start))
+				}
+				mu.Unlock()
+			}
+		}()
 	}
 
-	// Delete all data from the pathologies table
-	_, err = db.Exec("DELETE FROM pathologies")
-	if err != nil {
-		return fmt.Errorf("❌ Error deleting data from pathologies table: %w", err)
+feed:
+	for offset := 0; offset < len(texts); offset += batchSize {
+		select {
+		case offsets <- offset:
+		case <-ctx.Done():
+			break feed
+		}
 	}
-	return nil
+	close(offsets)
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return embeddings, nil
 }
 
-func RunImport(configPath string, spin *spinner.Spinner) error {
+// printReport logs the summary of an import run
+func printReport(report ImportReport) {
+	fmt.Println()
+	configPkg.Log.Infof("✅ Import run %s: %d succeeded, %d skipped, %d failed",
+		report.RunID, len(report.Succeeded), len(report.Skipped), len(report.Failed))
+	configPkg.Log.Infof("✅ Labels: %d new, %d updated, %d unchanged, %d removed, %d embedded",
+		report.Totals.New, report.Totals.Updated, report.Totals.Unchanged, report.Totals.Removed, report.Totals.Embedded)
+	if len(report.Skipped) > 0 {
+		configPkg.Log.Infof("✅ Skipped (completed by a previous attempt): %s", strings.Join(report.Skipped, ", "))
+	}
 
-	configPkg.InitLogger()
+	failed := make([]string, 0, len(report.Failed))
+	for pathology := range report.Failed {
+		failed = append(failed, pathology)
+	}
+	sort.Strings(failed)
+	for _, pathology := range failed {
+		configPkg.Log.Errorf("❌ %s: %s", pathology, report.Failed[pathology])
+	}
+}
 
-	spin.Suffix = " Load Config..."
-	spin.Color("green", "bold")
-	spin.Start()
+// ImportOptions changes where RunImport reads the drug labels from
+type ImportOptions struct {
+	// BulkPath is an OpenFDA drug label bulk file or directory. When empty
+	// the labels are fetched from the OpenFDA API.
+	BulkPath string
 
-	config, err := configPkg.LoadConfig(configPath)
-	if err != nil {
-		spin.Stop()
-		fmt.Println()
-		configPkg.Log.Fatalf("❌ Error reading config file: %v", err)
-		return err
-	}
-	spin.Stop()
+	// Workers overrides the number of concurrent embedding requests
+	Workers int
 
-	fmt.Println()
-	fmt.Println()
-	configPkg.Log.Infof("✅ Config Loaded \n")
+	// Resume is the ID of an interrupted run to continue: the pathologies
+	// it completed are skipped
+	Resume string
+}
+
+func RunImport(config *configPkg.Config, options ImportOptions, spin *spinner.Spinner) error {
+
+	spin.Color("green", "bold")
 	configPkg.Log.Infof("✅ Model use for Embedding generation: %s\n", config.Models.Embedding.Name)
 	fmt.Println()
 
@@ -275,59 +412,167 @@ func RunImport(configPath string, spin *spinner.Spinner) error {
 	pathologies, err := configPkg.LoadPathologies(config.Pathologie.File)
 	if err != nil {
 		spin.Stop()
-		fmt.Println()
-		configPkg.Log.Fatalf("❌ Error parsing JSON contents of file pathologies: %v", err)
-		return err
+		return fmt.Errorf("❌ Error parsing JSON contents of file pathologies: %w", err)
 	}
 
 	spin.Stop()
 	configPkg.Log.Infof("✅ Pathologies Loaded \n")
 
-	dsn := fmt.Sprintf("%s:%s@tcp(%s:%s)/health", config.MySQL.User, config.MySQL.Password, config.MySQL.Server, config.MySQL.Port)
-	db, err := sql.Open("mysql", dsn)
+	embeddings, err := embedding.New(context.Background(), config)
 	if err != nil {
-		fmt.Println()
-		configPkg.Log.Fatalf("❌ Error connecting to MySQL: %v", err)
-		return err
+		return fmt.Errorf("❌ Error initializing embedding model: %w", err)
 	}
-	defer db.Close()
+	configPkg.Log.Infof("✅ Embedding model %s produces %d dimensions", embeddings.Model, embeddings.Dimension)
 
-	spin.Suffix = " Init Database..."
-	spin.Start()
+	vectorStore, err := store.Open(config)
+	if err != nil {
+		return fmt.Errorf("❌ Error opening store: %w", err)
+	}
+	defer vectorStore.Close()
+
+	if err := embeddings.Check(context.Background(), vectorStore, true); err != nil {
+		return fmt.Errorf("❌ Embedding model check failed: %w", err)
+	}
 
-	// Initialize the database by clearing the tables
-	err = initDatabase(db)
+	stored, err := vectorStore.ListPathologies(context.Background())
 	if err != nil {
-		spin.Stop()
-		fmt.Println()
-		configPkg.Log.Fatalf("❌ Error initializing database: %v", err)
-		return err
+		return fmt.Errorf("❌ Error listing pathologies: %w", err)
+	}
+	previous := make(map[string]store.Pathology, len(stored))
+	for _, p := range stored {
+		previous[p.Name] = p
+	}
+
+	// Pathologies removed from the pathologies file are dropped with their medications
+	for name, p := range previous {
+		if _, ok := pathologies.Pathologies[name]; ok {
+			continue
+		}
+		if err := vectorStore.DeletePathology(context.Background(), p.ID); err != nil {
+			return fmt.Errorf("❌ Error deleting pathology %s: %w", name, err)
+		}
+		configPkg.Log.Infof("✅ Pathology %s removed", name)
 	}
-	spin.Stop()
-	configPkg.Log.Infof("✅ Tables pathologies and medicationv have been cleared.")
 
 	spin.Suffix = " Insert Drug and Pathologies in DB ...\n"
 	spin.Start()
 
-	for pathology := range pathologies.Pathologies {
-		data, err := fetchMedications(pathology)
+	var bulk map[string]openfda.Response
+	if options.BulkPath != "" {
+		spin.Stop()
+		spin.Suffix = " Read OpenFDA bulk files ..."
+		spin.Start()
+
+		names := make([]string, 0, len(pathologies.Pathologies))
+		for pathology := range pathologies.Pathologies {
+			names = append(names, pathology)
+		}
+		bulk, err = openfda.MatchBulkLabels(options.BulkPath, names)
 		if err != nil {
 			spin.Stop()
-			fmt.Println()
-			configPkg.Log.Fatalf("❌ Error fetching medications for pathology: %s - %v", pathology, err)
+			return fmt.Errorf("❌ Error reading OpenFDA bulk files: %w", err)
+		}
+		spin.Stop()
+		configPkg.Log.Infof("✅ OpenFDA bulk files read from %s", options.BulkPath)
+
+		spin.Suffix = " Insert Drug and Pathologies in DB ...\n"
+		spin.Start()
+	}
+
+	client := newOpenFDAClient(config)
+	model := embeddings.Model
+
+	source := "openfda api"
+	if options.BulkPath != "" {
+		source = "bulk " + options.BulkPath
+	}
+	var journal *Journal
+	if options.Resume != "" {
+		journal, err = OpenJournal(config.Import.JournalDir, options.Resume, model, source)
+	} else {
+		journal, err = NewJournal(config.Import.JournalDir, model, source)
+	}
+	if err != nil {
+		spin.Stop()
+		return fmt.Errorf("❌ Error opening import journal: %w", err)
+	}
+	defer journal.Close()
+	configPkg.Log.Infof("✅ Import run %s (journal %s)", journal.RunID, journal.Path)
+
+	importer := &Importer{
+		Store:      vectorStore,
+		Embeddings: embeddings,
+		Workers:    config.Import.Workers,
+		BatchSize:  config.Import.BatchSize,
+		Progress: func(pathology string, done, total int, elapsed time.Duration) {
+			spin.Lock()
+			spin.Suffix = fmt.Sprintf(" %s: %d/%d labels embedded (%.1f labels/s)", pathology, done, total, float64(done)/elapsed.Seconds())
+			spin.Unlock()
+		},
+	}
+	if options.Workers > 0 {
+		importer.Workers = options.Workers
+	}
+
+	// A pathology that fails is reported at the end and keeps its stored data
+	report := ImportReport{RunID: journal.RunID, Failed: make(map[string]string)}
+	names := make([]string, 0, len(pathologies.Pathologies))
+	for pathology := range pathologies.Pathologies {
+		names = append(names, pathology)
+	}
+	sort.Strings(names)
+
+	for _, pathology := range names {
+		if journal.Completed(pathology) {
+			report.Skipped = append(report.Skipped, pathology)
 			continue
 		}
+
+		var data openfda.Response
+		if bulk != nil {
+			data = bulk[pathology]
+		} else {
+			data, err = client.SearchLabels(context.Background(), pathology)
+			if err != nil {
+				configPkg.Log.Errorf("❌ Error fetching medications for pathology: %s - %v", pathology, err)
+				report.Failed[pathology] = err.Error()
+				if err := journal.Failed(pathology, err); err != nil {
+					return err
+				}
+				continue
+			}
+		}
 		details := pathologies.Pathologies[pathology]
 
-		err = InsertData(db, pathology, details, data, config.Models.Generation.Name)
+		stats, err := importer.InsertData(context.Background(), pathology, details, data, previous[pathology])
 		if err != nil {
-			spin.Stop()
-			fmt.Println()
-			configPkg.Log.Fatalf("❌ Error inserting data for pathology: %s - %v", pathology, err)
+			configPkg.Log.Errorf("❌ Error inserting data for pathology: %s - %v", pathology, err)
+			report.Failed[pathology] = err.Error()
+			if err := journal.Failed(pathology, err); err != nil {
+				return err
+			}
+			continue
 		}
+		if err := journal.Done(pathology, stats); err != nil {
+			return err
+		}
+		report.Succeeded = append(report.Succeeded, pathology)
+		report.Totals.add(stats)
+		configPkg.Log.Infof("✅ %s: %d new, %d updated, %d unchanged, %d removed - %d labels embedded in %s (%.1f labels/s)",
+			pathology, stats.New, stats.Updated, stats.Unchanged, stats.Removed,
+			stats.Embedded, stats.Duration.Round(time.Millisecond), float64(stats.Embedded)/stats.Duration.Seconds())
 	}
 	spin.Stop()
-	configPkg.Log.Infof("✅ Data inserted successfully.")
+
+	if err := journal.End(report); err != nil {
+		return err
+	}
+	printReport(report)
+
+	if len(report.Failed) > 0 {
+		return fmt.Errorf("❌ %d of %d pathologies failed, resume with --resume %s", len(report.Failed), len(names), report.RunID)
+	}
+	configPkg.Log.Infof("✅ Data synchronized successfully.")
 
 	return nil
 }
@@ -0,0 +1,103 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"testing"
+
+	configPkg "github.com/colussim/go-mysql-ai/pkg/config"
+	"github.com/colussim/go-mysql-ai/pkg/embedding"
+	"github.com/colussim/go-mysql-ai/pkg/openfda"
+	"github.com/colussim/go-mysql-ai/pkg/store"
+)
+
+// newTestEmbeddings returns an embedding service backed by a fake Ollama
+// server answering every text with the same vector
+func newTestEmbeddings(t *testing.T) *embedding.Service {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request struct {
+			Input []string `json:"input"`
+		}
+		json.NewDecoder(r.Body).Decode(&request)
+		vectors := make([][]float32, len(request.Input))
+		for i := range vectors {
+			vectors[i] = []float32{1, 0}
+		}
+		json.NewEncoder(w).Encode(map[string]any{"embeddings": vectors})
+	}))
+	t.Cleanup(server.Close)
+
+	service, err := embedding.NewModel(context.Background(), configPkg.ProviderConfig{Provider: "ollama", URL: server.URL}, "test-model")
+	if err != nil {
+		t.Fatalf("NewModel: %v", err)
+	}
+	return service
+}
+
+func label(setID, brand string) openfda.Label {
+	var l openfda.Label
+	l.SetID = setID
+	l.Version = "1"
+	l.OpenFDA.BrandName = []string{brand}
+	l.OpenFDA.ActiveIngredient = []string{"ibuprofen"}
+	l.IndicationsAndUsage = []string{"temporarily relieves minor aches and pains"}
+	return l
+}
+
+func TestInsertDataReplacesLegacyMedications(t *testing.T) {
+	ctx := context.Background()
+	memory := store.NewMemory()
+	importer := &Importer{Store: memory, Embeddings: newTestEmbeddings(t)}
+
+	pathology := store.Pathology{Name: "headache", Embedding: []float64{1, 0}}
+	if err := memory.UpsertPathology(ctx, &pathology); err != nil {
+		t.Fatal(err)
+	}
+	// Two medications imported before set IDs, and one keyed label
+	for _, m := range []*store.Medication{
+		{PathologyID: pathology.ID, DrugName: "Legacy A", Embedding: []float64{1, 0}},
+		{PathologyID: pathology.ID, DrugName: "Legacy B", Embedding: []float64{1, 0}},
+		{PathologyID: pathology.ID, SetID: "kept", DrugName: "Kept", Embedding: []float64{1, 0}},
+	} {
+		if err := memory.UpsertMedication(ctx, m); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	data := openfda.Response{Results: []openfda.Label{label("kept", "Kept"), label("new", "New")}}
+	stats, err := importer.InsertData(ctx, "headache", configPkg.PathologyDetail{}, data, pathology)
+	if err != nil {
+		t.Fatalf("InsertData: %v", err)
+	}
+	if stats.Removed != 2 {
+		t.Errorf("removed %d medications, want the 2 legacy ones", stats.Removed)
+	}
+
+	stored, err := memory.ListMedications(ctx, pathology.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, m := range stored {
+		if m.SetID == "" {
+			t.Errorf("legacy medication %s is still stored", m.DrugName)
+		}
+		names = append(names, m.DrugName)
+	}
+	sort.Strings(names)
+	if len(names) != 2 || names[0] != "Kept" || names[1] != "New" {
+		t.Errorf("stored %v, want [Kept New]", names)
+	}
+
+	// The next import has nothing left to remove
+	stats, err = importer.InsertData(ctx, "headache", configPkg.PathologyDetail{}, data, pathology)
+	if err != nil {
+		t.Fatalf("InsertData: %v", err)
+	}
+	if stats.Removed != 0 || stats.Unchanged != 2 {
+		t.Errorf("second import removed %d and left %d unchanged, want 0 and 2", stats.Removed, stats.Unchanged)
+	}
+}
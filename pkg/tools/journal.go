@@ -0,0 +1,176 @@
+package tools
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const defaultJournalDir = "imports"
+
+// LabelStatus is what an import did to one drug label
+type LabelStatus struct {
+	SetID  string `json:"set_id"`
+	Status string `json:"status"`
+}
+
+// journalEntry is one line of a journal file
+type journalEntry struct {
+	Time      time.Time     `json:"time"`
+	Event     string        `json:"event"`
+	RunID     string        `json:"run_id,omitempty"`
+	Model     string        `json:"model,omitempty"`
+	Source    string        `json:"source,omitempty"`
+	Pathology string        `json:"pathology,omitempty"`
+	SetID     string        `json:"set_id,omitempty"`
+	Status    string        `json:"status,omitempty"`
+	Error     string        `json:"error,omitempty"`
+	Stats     *ImportStats  `json:"stats,omitempty"`
+	Report    *ImportReport `json:"report,omitempty"`
+}
+
+// Journal records the progress of an import run in a JSON lines file, one
+// file per run, so an interrupted run can be resumed where it stopped.
+type Journal struct {
+	RunID string
+	Model string
+	Path  string
+
+	file      *os.File
+	encoder   *json.Encoder
+	completed map[string]bool
+}
+
+// NewJournal starts the journal of a new run in dir
+func NewJournal(dir, model, source string) (*Journal, error) {
+	if dir == "" {
+		dir = defaultJournalDir
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("❌ Error creating journal directory: %w", err)
+	}
+
+	// Runs started within the same second get a suffix
+	base := time.Now().Format("20060102-150405")
+	runID, path := base, ""
+	var file *os.File
+	for i := 2; ; i++ {
+		path = filepath.Join(dir, runID+".jsonl")
+		var err error
+		file, err = os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o644)
+		if err == nil {
+			break
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return nil, fmt.Errorf("❌ Error creating journal: %w", err)
+		}
+		runID = fmt.Sprintf("%s-%d", base, i)
+	}
+
+	j := &Journal{RunID: runID, Model: model, Path: path, file: file, encoder: json.NewEncoder(file), completed: make(map[string]bool)}
+	if err := j.write(journalEntry{Event: "start", RunID: runID, Model: model, Source: source}); err != nil {
+		file.Close()
+		return nil, err
+	}
+	return j, nil
+}
+
+// OpenJournal reopens the journal of runID to resume it. The run must have
+// been made with the same embedding model, otherwise its completed
+// pathologies would be left with vectors of another model.
+func OpenJournal(dir, runID, model, source string) (*Journal, error) {
+	if dir == "" {
+		dir = defaultJournalDir
+	}
+	path := filepath.Join(dir, runID+".jsonl")
+
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("❌ Unknown import run: %s", runID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("❌ Error opening journal: %w", err)
+	}
+
+	j := &Journal{RunID: runID, Model: model, Path: path, completed: make(map[string]bool)}
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var entry journalEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			// The last line may be truncated by a crash
+			continue
+		}
+		switch entry.Event {
+		case "start":
+			if entry.Model != model {
+				file.Close()
+				return nil, fmt.Errorf("❌ Import run %s used model %s, not %s", runID, entry.Model, model)
+			}
+		case "pathology":
+			j.completed[entry.Pathology] = entry.Status == "done"
+		}
+	}
+	err = scanner.Err()
+	file.Close()
+	if err != nil {
+		return nil, fmt.Errorf("❌ Error reading journal: %w", err)
+	}
+
+	j.file, err = os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("❌ Error opening journal: %w", err)
+	}
+	j.encoder = json.NewEncoder(j.file)
+	if err := j.write(journalEntry{Event: "resume", RunID: runID, Model: model, Source: source}); err != nil {
+		j.file.Close()
+		return nil, err
+	}
+	return j, nil
+}
+
+// Completed reports whether a previous attempt of the run imported pathology
+func (j *Journal) Completed(pathology string) bool {
+	return j.completed[pathology]
+}
+
+// Done records a pathology and the status of each of its labels
+func (j *Journal) Done(pathology string, stats ImportStats) error {
+	for _, label := range stats.Labels {
+		if err := j.write(journalEntry{Event: "label", Pathology: pathology, SetID: label.SetID, Status: label.Status}); err != nil {
+			return err
+		}
+	}
+	j.completed[pathology] = true
+	return j.write(journalEntry{Event: "pathology", Pathology: pathology, Status: "done", Stats: &stats})
+}
+
+// Failed records a pathology that could not be imported
+func (j *Journal) Failed(pathology string, err error) error {
+	return j.write(journalEntry{Event: "pathology", Pathology: pathology, Status: "failed", Error: err.Error()})
+}
+
+// End records the report of the run
+func (j *Journal) End(report ImportReport) error {
+	return j.write(journalEntry{Event: "end", RunID: j.RunID, Report: &report})
+}
+
+func (j *Journal) Close() error {
+	return j.file.Close()
+}
+
+// write appends an entry and syncs it, so it survives a crash of the import
+func (j *Journal) write(entry journalEntry) error {
+	entry.Time = time.Now()
+	if err := j.encoder.Encode(entry); err != nil {
+		return fmt.Errorf("❌ Error writing journal: %w", err)
+	}
+	if err := j.file.Sync(); err != nil {
+		return fmt.Errorf("❌ Error writing journal: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,139 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/briandowns/spinner"
+	configPkg "github.com/colussim/go-mysql-ai/pkg/config"
+	"github.com/colussim/go-mysql-ai/pkg/embedding"
+	"github.com/colussim/go-mysql-ai/pkg/store"
+)
+
+// ReembedOptions selects the model RunReembed moves the stored vectors to
+type ReembedOptions struct {
+	Model string
+
+	// Workers overrides the number of concurrent embedding requests
+	Workers int
+}
+
+// RunReembed embeds the stored pathologies and medications again with
+// options.Model, from their stored text, without fetching anything from
+// OpenFDA. The new vectors are staged next to the current ones, which keep
+// being served, and swapped in at once when all of them are written.
+func RunReembed(config *configPkg.Config, options ReembedOptions, spin *spinner.Spinner) error {
+
+	ctx := context.Background()
+
+	if options.Model == "" {
+		return fmt.Errorf("❌ The model to re-embed with is required")
+	}
+
+	pathologies, err := configPkg.LoadPathologies(config.Pathologie.File)
+	if err != nil {
+		return fmt.Errorf("❌ Error parsing JSON contents of file pathologies: %w", err)
+	}
+
+	embeddings, err := embedding.NewModel(ctx, config.Models.Embedding.ProviderConfig, options.Model)
+	if err != nil {
+		return err
+	}
+	configPkg.Log.Infof("✅ Embedding model %s produces %d dimensions", embeddings.Model, embeddings.Dimension)
+
+	vectorStore, err := store.Open(config)
+	if err != nil {
+		return fmt.Errorf("❌ Error opening store: %w", err)
+	}
+	defer vectorStore.Close()
+
+	reembedder, ok := vectorStore.(store.Reembedder)
+	if !ok {
+		return fmt.Errorf("❌ The %s store cannot re-embed its vectors", config.Store.Type)
+	}
+	current, _, err := vectorStore.EmbeddingModel(ctx)
+	if err != nil {
+		return err
+	}
+	configPkg.Log.Infof("✅ Re-embedding from %s to %s", current, options.Model)
+
+	storedPathologies, err := vectorStore.ListPathologies(ctx)
+	if err != nil {
+		return err
+	}
+	storedMedications, err := vectorStore.ListMedications(ctx, 0)
+	if err != nil {
+		return err
+	}
+
+	reembedding, err := reembedder.BeginReembed(ctx, embeddings.Model, embeddings.Dimension)
+	if err != nil {
+		return err
+	}
+	if err := reembed(ctx, reembedding, embeddings, config, pathologies, storedPathologies, storedMedications, options, spin); err != nil {
+		if abortErr := reembedding.Abort(ctx); abortErr != nil {
+			configPkg.Log.Errorf("❌ Error dropping the staged vectors: %v", abortErr)
+		}
+		return err
+	}
+
+	configPkg.Log.Infof("✅ %d pathologies and %d medications re-embedded with %s", len(storedPathologies), len(storedMedications), embeddings.Model)
+	configPkg.Log.Infof("✅ Set models.embedding.name to %s so the next start matches the stored vectors", embeddings.Model)
+	return nil
+}
+
+func reembed(ctx context.Context, reembedding store.Reembedding, embeddings *embedding.Service, config *configPkg.Config,
+	pathologies *configPkg.Pathology, storedPathologies []store.Pathology, storedMedications []store.Medication,
+	options ReembedOptions, spin *spinner.Spinner) error {
+
+	start := time.Now()
+	importer := &Importer{
+		Embeddings: embeddings,
+		Workers:    config.Import.Workers,
+		BatchSize:  config.Import.BatchSize,
+		Progress: func(name string, done, total int, elapsed time.Duration) {
+			spin.Lock()
+			spin.Suffix = fmt.Sprintf(" %s: %d/%d embedded (%.1f/s)", name, done, total, float64(done)/elapsed.Seconds())
+			spin.Unlock()
+		},
+	}
+	if options.Workers > 0 {
+		importer.Workers = options.Workers
+	}
+
+	spin.Start()
+	defer spin.Stop()
+
+	// Pathologies are embedded from the pathologies file, as by the import
+	texts := make([]string, len(storedPathologies))
+	for i, p := range storedPathologies {
+		texts[i] = pathologyText(p.Name, pathologies.Pathologies[p.Name])
+	}
+	vectors, err := importer.embed(ctx, "pathologies", texts, start)
+	if err != nil {
+		return err
+	}
+	for i, p := range storedPathologies {
+		if err := reembedding.SetPathologyVector(ctx, p.ID, contentHash(embeddings.Model, texts[i]), vectors[i]); err != nil {
+			return err
+		}
+	}
+
+	// Medications are embedded from their stored columns
+	texts = make([]string, len(storedMedications))
+	for i := range storedMedications {
+		texts[i] = medicationText(storedMedications[i].Pathology, &storedMedications[i])
+	}
+	vectors, err = importer.embed(ctx, "medications", texts, start)
+	if err != nil {
+		return err
+	}
+	for i, m := range storedMedications {
+		if err := reembedding.SetMedicationVector(ctx, m.ID, medicationHash(embeddings.Model, texts[i], &m), vectors[i]); err != nil {
+			return err
+		}
+	}
+
+	return reembedding.Commit(ctx)
+}
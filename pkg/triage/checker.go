@@ -0,0 +1,236 @@
+package triage
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/colussim/go-mysql-ai/pkg/store"
+)
+
+// Match is a rule a question matched
+type Match struct {
+	Rule
+	// Terms found in the question, or the description it is close to
+	Matched []string
+	// Similarity with the description, for the rules matched by embedding
+	Similarity float64
+}
+
+// Checker evaluates the rules of a triage file. It is safe for concurrent
+// use: WithEmbeddings returns a new checker.
+type Checker struct {
+	threshold float64
+	rules     []Rule
+	terms     map[string]*regexp.Regexp
+	model     string
+	vectors   [][][]float64
+}
+
+// Ages stated in a question: "a 2-month-old", "6 weeks old", "aged 3 months"
+var (
+	statedAge     = regexp.MustCompile(`\b(\d+)[\s-]*(day|week|month|year)s?[\s-]*(?:old|baby|infant)\b|\baged?\s+(\d+)\s*(day|week|month|year)s?\b`)
+	newbornWord   = regexp.MustCompile(`\bnew-?borns?\b`)
+	termSeparator = regexp.MustCompile(`\s+`)
+)
+
+// NewChecker compiles the terms of a rule set. A nil set checks nothing.
+func NewChecker(set *RuleSet) *Checker {
+	c := &Checker{threshold: DefaultThreshold, terms: make(map[string]*regexp.Regexp)}
+	if set == nil {
+		return c
+	}
+	c.threshold = set.Threshold
+	c.rules = set.Rules
+	for _, rule := range c.rules {
+		terms := slices.Clone(rule.Keywords)
+		for _, combination := range rule.Combinations {
+			terms = append(terms, combination...)
+		}
+		for _, term := range terms {
+			if _, ok := c.terms[term]; ok {
+				continue
+			}
+			var alternatives []string
+			for _, alternative := range strings.Split(term, "|") {
+				if alternative = strings.TrimSpace(alternative); alternative != "" {
+					alternatives = append(alternatives, termSeparator.ReplaceAllString(regexp.QuoteMeta(alternative), `\s+`))
+				}
+			}
+			c.terms[term] = regexp.MustCompile(`\b(?:` + strings.Join(alternatives, "|") + `)\b`)
+		}
+	}
+	return c
+}
+
+// Len returns the number of rules
+func (c *Checker) Len() int {
+	return len(c.rules)
+}
+
+// Model returns the embedding model of the descriptions, or an empty name
+// when they are not embedded
+func (c *Checker) Model() string {
+	return c.model
+}
+
+// WithEmbeddings returns a checker with the descriptions of the rules
+// embedded by embed with model
+func (c *Checker) WithEmbeddings(ctx context.Context, model string, embed func(context.Context, []string) ([][]float64, error)) (*Checker, error) {
+	var descriptions []string
+	for _, rule := range c.rules {
+		descriptions = append(descriptions, rule.Descriptions...)
+	}
+
+	embedded := *c
+	embedded.model = model
+	embedded.vectors = make([][][]float64, len(c.rules))
+	if len(descriptions) == 0 {
+		return &embedded, nil
+	}
+	vectors, err := embed(ctx, descriptions)
+	if err != nil {
+		return nil, fmt.Errorf("❌ Error embedding the triage descriptions: %w", err)
+	}
+	if len(vectors) != len(descriptions) {
+		return nil, fmt.Errorf("❌ Got %d embeddings for %d triage descriptions", len(vectors), len(descriptions))
+	}
+	for i, rule := range c.rules {
+		embedded.vectors[i], vectors = vectors[:len(rule.Descriptions)], vectors[len(rule.Descriptions):]
+	}
+	return &embedded, nil
+}
+
+// Check returns the most urgent rule whose keywords or combinations are
+// found in the question, or nil. ageBand is the age band of the patient
+// profile, when known.
+func (c *Checker) Check(text, ageBand string) *Match {
+	text = normalizeText(text)
+	var best *Match
+	for _, rule := range c.rules {
+		if !c.ageMatches(rule, text, ageBand) {
+			continue
+		}
+		matched := c.matchTerms(rule, text)
+		if matched != nil && (best == nil || moreUrgent(rule, best.Rule)) {
+			best = &Match{Rule: rule, Matched: matched}
+		}
+	}
+	return best
+}
+
+// CheckSimilar returns the most urgent rule with a description close
+// enough to the question embedding, or nil. The descriptions must have
+// been embedded in the same space.
+func (c *Checker) CheckSimilar(text, ageBand string, queryEmbedding []float64) *Match {
+	if c.vectors == nil || len(queryEmbedding) == 0 {
+		return nil
+	}
+	text = normalizeText(text)
+	var best *Match
+	for i, rule := range c.rules {
+		if !c.ageMatches(rule, text, ageBand) {
+			continue
+		}
+		for j, vector := range c.vectors[i] {
+			similarity := store.CosineSimilarity(queryEmbedding, vector)
+			if similarity < c.threshold {
+				continue
+			}
+			if best == nil || moreUrgent(rule, best.Rule) || (best.Rule.ID == rule.ID && similarity > best.Similarity) {
+				best = &Match{Rule: rule, Matched: []string{rule.Descriptions[j]}, Similarity: similarity}
+			}
+		}
+	}
+	return best
+}
+
+// matchTerms returns the terms of the first keyword or combination of a
+// rule found in a normalized question, or nil
+func (c *Checker) matchTerms(rule Rule, text string) []string {
+	if matched := c.findKeyword(rule, text); matched != nil {
+		return matched
+	}
+	return c.findCombination(rule, text)
+}
+
+func (c *Checker) findKeyword(rule Rule, text string) []string {
+	for _, keyword := range rule.Keywords {
+		if found := c.terms[keyword].FindString(text); found != "" {
+			return []string{found}
+		}
+	}
+	return nil
+}
+
+func (c *Checker) findCombination(rule Rule, text string) []string {
+	for _, combination := range rule.Combinations {
+		var matched []string
+		for _, term := range combination {
+			found := c.terms[term].FindString(text)
+			if found == "" {
+				matched = nil
+				break
+			}
+			matched = append(matched, found)
+		}
+		if matched != nil {
+			return matched
+		}
+	}
+	return nil
+}
+
+// ageMatches tells whether the patient meets the age condition of a rule
+func (c *Checker) ageMatches(rule Rule, text, ageBand string) bool {
+	if rule.Age == nil {
+		return true
+	}
+	if ageBand != "" && slices.Contains(rule.Age.AgeBands, ageBand) {
+		return true
+	}
+	months, ok := ageInMonths(text)
+	return ok && rule.Age.UnderMonths > 0 && months < rule.Age.UnderMonths
+}
+
+// ageInMonths returns the age stated in a question, in whole months
+func ageInMonths(text string) (int, bool) {
+	if newbornWord.MatchString(text) {
+		return 0, true
+	}
+	match := statedAge.FindStringSubmatch(text)
+	if match == nil {
+		return 0, false
+	}
+	number, unit := match[1], match[2]
+	if number == "" {
+		number, unit = match[3], match[4]
+	}
+	n, err := strconv.Atoi(number)
+	if err != nil {
+		return 0, false
+	}
+	switch unit {
+	case "day":
+		return n / 30, true
+	case "week":
+		return n * 7 / 30, true
+	case "month":
+		return n, true
+	default:
+		return n * 12, true
+	}
+}
+
+// moreUrgent tells whether rule a is more urgent than rule b
+func moreUrgent(a, b Rule) bool {
+	return slices.Index(Urgencies, a.Urgency) > slices.Index(Urgencies, b.Urgency)
+}
+
+// normalizeText lowercases a question and straightens its apostrophes
+func normalizeText(text string) string {
+	return strings.ReplaceAll(strings.ToLower(text), "’", "'")
+}
@@ -0,0 +1,88 @@
+package triage
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestShippedRules(t *testing.T) {
+	set, err := Load("../../config/triage.json")
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	checker := NewChecker(set)
+
+	tests := []struct {
+		question string
+		ageBand  string
+		want     string
+	}{
+		{"Headache with a stiff neck and I feel confused", "", "meningitis"},
+		{"My son is having a seizure", "", "seizure"},
+		{"She had a fit and fell", "", "seizure"},
+		{"My new shoes are well-fitting but my feet ache", "", ""},
+		{"I think I overdosed on acetaminophen", "", "overdose"},
+		{"My toddler swallowed bleach", "", "overdose"},
+		{"My daughter swallowed poison from under the sink", "", "overdose"},
+		{"He swallowed all the pills in the box", "", "overdose"},
+		{"Our carbon monoxide alarm went off and I have a headache", "", "overdose"},
+		{"Could this be lead poisoning?", "", "overdose"},
+		{"I think my friend has alcohol poisoning", "", "overdose"},
+		{"I have diarrhea after food poisoning", "", ""},
+		{"Is loperamide fine for food poisoning?", "", ""},
+		{"My 2-month-old has a fever", "", "infant-fever"},
+		{"My 4-year-old has a fever", "", ""},
+		{"Diarrhea and no wet diapers since this morning", "infant", "infant-dehydration"},
+		{"Chest pain and I overdosed", "", "chest-pain"},
+		{"I have a mild headache", "", ""},
+	}
+	for _, tt := range tests {
+		var got string
+		if match := checker.Check(tt.question, tt.ageBand); match != nil {
+			got = match.ID
+		}
+		if got != tt.want {
+			t.Errorf("Check(%q) = %q, want %q", tt.question, got, tt.want)
+		}
+	}
+}
+
+func TestLoadChecksNegatives(t *testing.T) {
+	tests := []struct {
+		name    string
+		rules   string
+		wantErr string
+	}{
+		{
+			name:  "negative not matched",
+			rules: `{"id": "overdose", "urgency": "emergency", "advice": "Call now.", "keywords": ["overdose|poisoned"], "negatives": ["food poisoning"]}`,
+		},
+		{
+			name:    "negative matched by a keyword",
+			rules:   `{"id": "overdose", "urgency": "emergency", "advice": "Call now.", "keywords": ["overdose|poisoning"], "negatives": ["Food Poisoning"]}`,
+			wantErr: `matches its negative "Food Poisoning" on poisoning`,
+		},
+		{
+			name:    "negative matched by a combination",
+			rules:   `{"id": "meningitis", "urgency": "emergency", "advice": "Call now.", "combinations": [["fever", "stiff neck"]], "negatives": ["no fever, no stiff neck"]}`,
+			wantErr: `matches its negative "no fever, no stiff neck" on fever, stiff neck`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "triage.json")
+			if err := os.WriteFile(path, []byte(`{"rules": [`+tt.rules+`]}`), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			_, err := Load(path)
+			switch {
+			case tt.wantErr == "" && err != nil:
+				t.Errorf("Load: %v", err)
+			case tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)):
+				t.Errorf("Load error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,131 @@
+package triage
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+)
+
+// Urgencies of the rules, from the least to the most urgent: "urgent" is a
+// doctor or urgent care the same day, "emergency" the emergency services now
+var Urgencies = []string{"urgent", "emergency"}
+
+// Age bands a rule can be restricted to, as given in the patient profile
+var AgeBands = []string{"infant", "child", "adolescent", "adult", "senior"}
+
+// Default similarity a question needs with the description of a rule
+const DefaultThreshold = 0.85
+
+// RuleSet is a red-flag triage file
+type RuleSet struct {
+	// Similarity required with the descriptions of the rules
+	Threshold float64 `json:"threshold"`
+	Rules     []Rule  `json:"rules"`
+}
+
+// Rule is a red flag: a question matches it when it contains one of its
+// keywords or every term of one of its combinations, or when it is close
+// enough to one of its descriptions, and the patient meets its age
+// condition. A term lists its alternative wordings separated with "|":
+// "stiff neck|neck stiffness". Negatives are questions the rule must not
+// match by its terms, checked when the file is loaded.
+type Rule struct {
+	ID           string        `json:"id"`
+	Urgency      string        `json:"urgency"`
+	Title        string        `json:"title"`
+	Advice       string        `json:"advice"`
+	Keywords     []string      `json:"keywords,omitempty"`
+	Combinations [][]string    `json:"combinations,omitempty"`
+	Age          *AgeCondition `json:"age,omitempty"`
+	Descriptions []string      `json:"descriptions,omitempty"`
+	Negatives    []string      `json:"negatives,omitempty"`
+}
+
+// AgeCondition restricts a rule to the patients younger than UnderMonths,
+// as stated in the question ("a 2-month-old"), or in one of AgeBands, as
+// given in the patient profile
+type AgeCondition struct {
+	UnderMonths int      `json:"under_months,omitempty"`
+	AgeBands    []string `json:"age_bands,omitempty"`
+}
+
+// Load reads a triage file and checks its rules
+func Load(path string) (*RuleSet, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("❌ Error opening triage file: %w", err)
+	}
+	var set RuleSet
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, fmt.Errorf("❌ Error reading %s: %w", path, err)
+	}
+	if set.Threshold == 0 {
+		set.Threshold = DefaultThreshold
+	}
+
+	ids := make(map[string]bool)
+	for n := range set.Rules {
+		rule := &set.Rules[n]
+		if err := normalizeRule(rule); err != nil {
+			return nil, fmt.Errorf("❌ Rule %d of %s: %w", n+1, path, err)
+		}
+		if ids[rule.ID] {
+			return nil, fmt.Errorf("❌ Rule %d of %s: duplicate id %s", n+1, path, rule.ID)
+		}
+		ids[rule.ID] = true
+	}
+
+	checker := NewChecker(&set)
+	for _, rule := range set.Rules {
+		for _, negative := range rule.Negatives {
+			if matched := checker.matchTerms(rule, normalizeText(negative)); matched != nil {
+				return nil, fmt.Errorf("❌ The rule %s of %s matches its negative %q on %s", rule.ID, path, negative, strings.Join(matched, ", "))
+			}
+		}
+	}
+	return &set, nil
+}
+
+// normalizeRule lowercases the terms of a rule and checks its fields
+func normalizeRule(rule *Rule) error {
+	rule.ID = strings.TrimSpace(rule.ID)
+	if rule.ID == "" {
+		return fmt.Errorf("❌ A rule needs an id")
+	}
+	rule.Urgency = strings.ToLower(strings.TrimSpace(rule.Urgency))
+	if !slices.Contains(Urgencies, rule.Urgency) {
+		return fmt.Errorf("❌ Unknown urgency %q for %s, expected one of %s", rule.Urgency, rule.ID, strings.Join(Urgencies, ", "))
+	}
+	if strings.TrimSpace(rule.Advice) == "" {
+		return fmt.Errorf("❌ The rule %s has no advice", rule.ID)
+	}
+	if len(rule.Keywords) == 0 && len(rule.Combinations) == 0 && len(rule.Descriptions) == 0 {
+		return fmt.Errorf("❌ The rule %s has no keywords, combinations or descriptions", rule.ID)
+	}
+
+	for i := range rule.Keywords {
+		rule.Keywords[i] = strings.ToLower(strings.TrimSpace(rule.Keywords[i]))
+	}
+	for _, combination := range rule.Combinations {
+		if len(combination) < 2 {
+			return fmt.Errorf("❌ A combination of %s needs at least two terms", rule.ID)
+		}
+		for i := range combination {
+			combination[i] = strings.ToLower(strings.TrimSpace(combination[i]))
+		}
+	}
+	if rule.Age != nil {
+		for i, band := range rule.Age.AgeBands {
+			rule.Age.AgeBands[i] = strings.ToLower(strings.TrimSpace(band))
+			if !slices.Contains(AgeBands, rule.Age.AgeBands[i]) {
+				return fmt.Errorf("❌ Unknown age band %q for %s", band, rule.ID)
+			}
+		}
+		if rule.Age.UnderMonths <= 0 && len(rule.Age.AgeBands) == 0 {
+			return fmt.Errorf("❌ The age condition of %s needs under_months or age_bands", rule.ID)
+		}
+	}
+	return nil
+}